@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <ID>",
+	Short: "Explain a rule: what it checks, why it matters, references and framework mappings",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		catalogue, err := loadCatalogue()
+		if err != nil {
+			return err
+		}
+		for _, r := range catalogue {
+			if strings.EqualFold(r.ID, args[0]) {
+				return explainRule(r)
+			}
+		}
+		cmd.SilenceUsage = true
+		return fmt.Errorf("no rule with ID %q (see \"redcheck rules list\")", args[0])
+	},
+}
+
+func explainRule(r checks.Rule) error {
+	fmt.Printf("%s — %s\n", r.ID, r.Title)
+	meta := fmt.Sprintf("Severity %s · Category %s", r.Severity, r.Category)
+	if r.CISLevel > 0 {
+		profiles := "server, workstation"
+		if len(r.CISProfiles) > 0 {
+			profiles = strings.Join(r.CISProfiles, ", ")
+		}
+		meta += fmt.Sprintf(" · CIS Level %d (%s)", r.CISLevel, profiles)
+	}
+	fmt.Println(meta)
+
+	section := func(heading, text string) {
+		if text == "" {
+			text = "(not documented)"
+		}
+		fmt.Printf("\n%s\n", heading)
+		for _, line := range wrap(text, 76) {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	section("What it checks", r.Description)
+	section("Why it matters", r.Rationale)
+	section("Remediation", r.Remediation)
+
+	list := func(heading string, rows [][2]string) error {
+		var filled [][2]string
+		for _, row := range rows {
+			if row[1] != "" {
+				filled = append(filled, row)
+			}
+		}
+		if len(filled) == 0 {
+			return nil
+		}
+		fmt.Printf("\n%s\n", heading)
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, row := range filled {
+			fmt.Fprintf(tw, "  %s:\t%s\n", row[0], row[1])
+		}
+		return tw.Flush()
+	}
+	if ref := r.References; ref != nil {
+		rows := [][2]string{{"CIS", ref.CIS}, {"STIG", ref.STIG}, {"CCE", ref.CCE}}
+		for _, u := range ref.URLs {
+			rows = append(rows, [2]string{"URL", u})
+		}
+		if err := list("References", rows); err != nil {
+			return err
+		}
+	}
+	if m := r.Mappings; m != nil {
+		if err := list("Mappings", [][2]string{
+			{"NIST 800-53", strings.Join(m.NIST80053, ", ")},
+			{"PCI DSS", strings.Join(m.PCIDSS, ", ")},
+			{"MITRE ATT&CK", strings.Join(m.MITREATTACK, ", ")},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wrap breaks text into lines of at most width columns at spaces.
+func wrap(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+)
+
+// factsCmd groups commands that inspect the fact collector registry.
+var factsCmd = &cobra.Command{
+	Use:   "facts",
+	Short: "Inspect the facts rules can reference",
+}
+
+var flagFactsRulesDir string
+
+var factsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every registered fact with its description",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagFactsRulesDir != "" {
+			// registers the script facts the packs define
+			if _, _, err := loadRulePacks(false, flagFactsRulesDir); err != nil {
+				return err
+			}
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "FACT\tDESCRIPTION")
+		for _, c := range checks.Collectors() {
+			name := c.Name()
+			if checks.IsFamily(name) {
+				name += "<param>"
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", name, c.Description())
+		}
+		return tw.Flush()
+	},
+}
+
+func init() {
+	factsListCmd.Flags().StringVar(&flagFactsRulesDir, "rules", "", "Also list the script facts defined by the rule packs in this directory")
+	factsCmd.AddCommand(factsListCmd)
+	rootCmd.AddCommand(factsCmd)
+}
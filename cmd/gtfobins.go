@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/gtfobins"
+)
+
+var flagGTFOBinsFile string
+
+// gtfobinsCmd shows the dataset the recon checks match binaries against.
+var gtfobinsCmd = &cobra.Command{
+	Use:   "gtfobins [binary...]",
+	Short: "Show the GTFOBins dataset used by the recon checks",
+	Long: "Lists the binaries of the built-in GTFOBins dataset (or of --file) with the technique\n" +
+		"each gives away through sudo, SUID and file capabilities. Arguments restrict the\n" +
+		"list; versioned names such as python3.11 match their plain entry.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := gtfobins.Load(flagGTFOBinsFile)
+		if err != nil {
+			return err
+		}
+		names := data.Names()
+		if len(args) > 0 {
+			names = args
+		}
+		fmt.Printf("%s: %d binaries\n\n", data, len(data.Binaries))
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "BINARY\tCONTEXT\tTECHNIQUE")
+		for _, name := range names {
+			listed := false
+			for _, ctx := range []string{gtfobins.Sudo, gtfobins.SUID, gtfobins.Capabilities} {
+				if t, ok := data.Lookup(name, ctx); ok {
+					fmt.Fprintf(tw, "%s\t%s\t%s\n", name, ctx, t)
+					listed = true
+				}
+			}
+			if !listed {
+				fmt.Fprintf(tw, "%s\t-\tnot in dataset\n", name)
+			}
+		}
+		return tw.Flush()
+	},
+}
+
+func init() {
+	gtfobinsCmd.Flags().StringVar(&flagGTFOBinsFile, "file", "", "Dataset file to show instead of the built-in one")
+	rootCmd.AddCommand(gtfobinsCmd)
+}
@@ -12,9 +12,15 @@ var rootCmd = &cobra.Command{
 	Long:  "RedCheck scans a Linux host for high-ROI CIS Rocky v10 items and attacker-centric signals, then scores the posture with transparent math.",
 }
 
+// noBanner marks commands whose output is data (e.g. "rules export"); the
+// ASCII banner (printed on stderr) would only clutter captured output.
+const noBanner = "redcheck/no-banner"
+
 // Execute is called by main.main().
 func Execute() {
-	ui.Banner()
+	if c, _, err := rootCmd.Find(os.Args[1:]); err != nil || c.Annotations[noBanner] == "" {
+		ui.Banner()
+	}
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
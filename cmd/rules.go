@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/benchmark"
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+	"github.com/Shunsuiky0raku/redcheck/pkg/rules"
+)
+
+var (
+	flagExportOut   string
+	flagLintBuiltin bool
+
+	// rules list / show
+	flagCatRulesDir string
+	flagCatBuiltin  bool
+	flagCatProfile  string
+	flagCatCIS      bool
+	flagCatPE       bool
+	flagCatSelect   selection
+	flagCatSeverity string
+	flagCatFormat   string
+)
+
+// rulesCmd groups commands that work with rule packs.
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Work with rule packs",
+}
+
+var rulesExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the built-in rule pack to stdout or a file for customisation",
+	Long: `Write the rule pack compiled into this binary. Edit the copy and pass
+its directory to "scan --rules", optionally with --builtin=false.`,
+	Annotations: map[string]string{noBanner: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data := rules.BuiltInYAML()
+		if flagExportOut == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(flagExportOut, data, 0644); err != nil {
+			return fmt.Errorf("write %s: %w", flagExportOut, err)
+		}
+		fmt.Printf("Built-in rules written to: %s\n", flagExportOut)
+		return nil
+	},
+}
+
+var rulesLintCmd = &cobra.Command{
+	Use:   "lint [path...]",
+	Short: "Validate rule packs (files or directories) before rolling them out",
+	Long: `Check rule packs against the rule schema and the registered facts:
+unknown keys, unknown facts, invalid severities or categories, malformed
+conditions, duplicate IDs and missing remediation. Without arguments the
+built-in pack is linted. Exits non-zero when any error is found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		builtin, err := rules.LoadBuiltIn()
+		if err != nil {
+			return fmt.Errorf("load built-in rules:\n%w", err)
+		}
+
+		var issues []rules.Issue
+		var base, packs []*rules.Pack
+		if len(args) == 0 {
+			packs = []*rules.Pack{builtin}
+		} else if flagLintBuiltin {
+			base = []*rules.Pack{builtin}
+		}
+		for _, path := range args {
+			loaded, err := rules.LoadDir(path)
+			var perr *rules.Error
+			switch {
+			case errors.As(err, &perr):
+				for _, d := range perr.Diagnostics {
+					issues = append(issues, rules.Issue{Diagnostic: d, Level: rules.LevelError})
+				}
+			case err != nil:
+				return err
+			case len(loaded) == 0:
+				return fmt.Errorf("%s: no rule files (*.yml, *.yaml) found", path)
+			}
+			packs = append(packs, loaded...)
+		}
+		issues = append(issues, rules.LintPacks(base, packs)...)
+		// cis_level/cis_profile must agree with the built-in catalogues
+		for _, name := range benchmark.Names() {
+			b, err := benchmark.Load(name)
+			if err != nil {
+				return err
+			}
+			for _, p := range packs {
+				issues = append(issues, benchmark.Lint(b, p.Rules)...)
+			}
+		}
+
+		errCount, ruleCount := 0, 0
+		for _, is := range issues {
+			fmt.Println(is)
+			if is.Level == rules.LevelError {
+				errCount++
+			}
+		}
+		for _, p := range packs {
+			ruleCount += len(p.Rules)
+		}
+		fmt.Printf("%d rule(s) in %d pack(s): %d error(s), %d warning(s)\n",
+			ruleCount, len(packs), errCount, len(issues)-errCount)
+		if errCount > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("rule lint failed")
+		}
+		return nil
+	},
+}
+
+var rulesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the rules a scan with the same selection flags would run",
+	// output is meant for piping (--format json), so no banner
+	Annotations: map[string]string{noBanner: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagCatFormat != "table" && flagCatFormat != "json" {
+			return fmt.Errorf("--format must be table or json, got %q", flagCatFormat)
+		}
+		catalogue, err := loadCatalogue()
+		if err != nil {
+			return err
+		}
+		all := !flagCatCIS && !flagCatPE
+		selected, _, err := flagCatSelect.apply(checks.FilterForMode(all, flagCatCIS, flagCatPE, catalogue))
+		if err != nil {
+			return err
+		}
+
+		var out []ruleSummary
+		for _, r := range selected {
+			if flagCatSeverity != "" && !strings.EqualFold(r.Severity, flagCatSeverity) {
+				continue
+			}
+			out = append(out, summarizeRule(r))
+		}
+
+		if flagCatFormat == "json" {
+			if out == nil {
+				out = []ruleSummary{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(out)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tSEVERITY\tCATEGORY\tTAGS\tTITLE")
+		for _, r := range out {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.ID, r.Severity, r.Category, strings.Join(r.Tags, ","), r.Title)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d rule(s)\n", len(out))
+		return nil
+	},
+}
+
+var rulesShowCmd = &cobra.Command{
+	Use:   "show <ID>",
+	Short: "Print one rule in full: facts, expectations, remediation, fix and source",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		catalogue, err := loadCatalogue()
+		if err != nil {
+			return err
+		}
+		for _, r := range catalogue {
+			if strings.EqualFold(r.ID, args[0]) {
+				return printRule(r)
+			}
+		}
+		cmd.SilenceUsage = true
+		return fmt.Errorf("no rule with ID %q (see \"redcheck rules list\")", args[0])
+	},
+}
+
+// ruleSummary is one row of "rules list".
+type ruleSummary struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Category string   `json:"category"`
+	Severity string   `json:"severity"`
+	Tags     []string `json:"tags,omitempty"`
+	CISLevel int      `json:"cis_level,omitempty"`
+	Profiles []string `json:"cis_profile,omitempty"`
+	Facts    []string `json:"facts"`
+	Fix      bool     `json:"fix"`
+	Source   string   `json:"source"`
+}
+
+func summarizeRule(r checks.Rule) ruleSummary {
+	return ruleSummary{
+		ID:       r.ID,
+		Title:    r.Title,
+		Category: r.Category,
+		Severity: r.Severity,
+		Tags:     r.Tags,
+		CISLevel: r.CISLevel,
+		Profiles: r.CISProfiles,
+		Facts:    r.Condition().Facts(),
+		Fix:      r.HasFix(),
+		Source:   r.Source,
+	}
+}
+
+// loadCatalogue loads the rules selected by --builtin and --rules, tailored
+// by --profile.
+func loadCatalogue() ([]checks.Rule, error) {
+	if !flagCatBuiltin && flagCatRulesDir == "" {
+		return nil, fmt.Errorf("--builtin=false needs --rules <dir>")
+	}
+	packs, _, err := loadRulePacks(flagCatBuiltin, flagCatRulesDir)
+	if err != nil {
+		return nil, err
+	}
+	all, err := rules.Merge(packs...)
+	if err != nil {
+		return nil, fmt.Errorf("load rules:\n%w", err)
+	}
+	tailored, _, err := tailorRules(all, flagCatProfile)
+	return tailored, err
+}
+
+func printRule(r checks.Rule) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", label, value)
+		}
+	}
+	field("ID", r.ID)
+	field("Title", r.Title)
+	field("Category", r.Category)
+	field("Severity", r.Severity)
+	field("Tags", strings.Join(r.Tags, ", "))
+	if r.CISLevel > 0 {
+		profiles := "server, workstation"
+		if len(r.CISProfiles) > 0 {
+			profiles = strings.Join(r.CISProfiles, ", ")
+		}
+		field("CIS", fmt.Sprintf("Level %d (%s)", r.CISLevel, profiles))
+	}
+	field("Source", r.Source)
+	if r.RequiresRoot {
+		field("Requires root", "yes")
+	}
+	field("Fact", r.Fact)
+	field("Operator", r.Operator)
+	field("Expected", r.Expected)
+	field("Values", strings.Join(r.Values, ", "))
+	field("Expected all", strings.Join(r.ExpectedAll, ", "))
+	files := r.Files
+	if r.FilePath != "" {
+		files = append([]string{r.FilePath}, files...)
+	}
+	field("Files", strings.Join(files, ", "))
+	field("Remediation", r.Remediation)
+	if r.HasFix() {
+		field("Fix", "automatic (--emit-fix / --interactive)")
+	} else {
+		field("Fix", "none; follow the remediation manually")
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// condition trees are easiest to read in the YAML they were written in
+	tree := checks.Condition{All: r.All, Any: r.Any, Not: r.Not}
+	if tree.IsComposite() {
+		if err := printYAMLBlock("Condition", tree); err != nil {
+			return err
+		}
+	}
+	if r.When != nil {
+		if err := printYAMLBlock("When", r.When); err != nil {
+			return err
+		}
+	}
+	if r.HasFix() {
+		if err := printYAMLBlock("Fix", r.Fix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printYAMLBlock(label string, v any) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	fmt.Printf("%s:\n", label)
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+	return nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{rulesListCmd, rulesShowCmd, explainCmd} {
+		c.Flags().StringVar(&flagCatRulesDir, "rules", "", "Directory with extra rule files (*.yml, *.yaml)")
+		c.Flags().BoolVar(&flagCatBuiltin, "builtin", true, "Include the built-in rule pack")
+		c.Flags().StringVar(&flagCatProfile, "profile", "", "Tailoring profile to apply")
+	}
+	rulesListCmd.Flags().BoolVar(&flagCatCIS, "cis", false, "Only rules a --cis scan runs")
+	rulesListCmd.Flags().BoolVar(&flagCatPE, "pe", false, "Only rules a --pe scan runs")
+	addSelectionFlags(rulesListCmd, &flagCatSelect)
+	rulesListCmd.Flags().StringVar(&flagCatSeverity, "severity", "", "Only rules with exactly this severity")
+	rulesListCmd.Flags().StringVar(&flagCatFormat, "format", "table", "Output format: table or json")
+	rulesCmd.AddCommand(rulesListCmd, rulesShowCmd)
+
+	rulesLintCmd.Flags().BoolVar(&flagLintBuiltin, "builtin", true, "Report IDs that clash with the built-in pack")
+	rulesCmd.AddCommand(rulesLintCmd)
+
+	rulesExportCmd.Flags().StringVarP(&flagExportOut, "output", "o", "", "Write to this file instead of stdout")
+	rulesCmd.AddCommand(rulesExportCmd)
+	rootCmd.AddCommand(rulesCmd)
+}
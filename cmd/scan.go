@@ -5,18 +5,24 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 
+	"github.com/Shunsuiky0raku/redcheck/pkg/benchmark"
 	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+	"github.com/Shunsuiky0raku/redcheck/pkg/gtfobins"
 	htmlreport "github.com/Shunsuiky0raku/redcheck/pkg/report/html"
 	jsonreport "github.com/Shunsuiky0raku/redcheck/pkg/report/json"
+	"github.com/Shunsuiky0raku/redcheck/pkg/rules"
 	"github.com/Shunsuiky0raku/redcheck/pkg/scoring"
 )
 
@@ -30,6 +36,12 @@ var (
 	flagJSON        string
 	flagHTML        string
 	flagRulesDir    string
+	flagBuiltin     bool
+	flagProfile     string
+	flagWaivers     string
+	flagAllowExec   []string
+	flagGTFOBins    string
+	flagSelect      selection
 	flagEmitFix     string
 	flagInteractive bool
 
@@ -55,34 +67,72 @@ var scanCmd = &cobra.Command{
 		}
 
 		checks.Verbose = flagVerbose
+		for _, v := range []string{flagRulesDir, flagProfile, flagWaivers, flagGTFOBins} {
+			if strings.HasPrefix(v, remotePrefix) {
+				return fmt.Errorf("%s: %s paths need --ssh-host", v, remotePrefix)
+			}
+		}
+		for _, c := range flagAllowExec {
+			if !filepath.IsAbs(c) {
+				return fmt.Errorf("--allow-exec %q: give the absolute path of the binary", c)
+			}
+		}
+		checks.AllowedCommands = flagAllowExec
+		if flagGTFOBins != "" {
+			data, err := gtfobins.Load(flagGTFOBins)
+			if err != nil {
+				return fmt.Errorf("load GTFOBins dataset: %w", err)
+			}
+			checks.GTFOBins = data
+		}
 
 		// 2) load rules
-		builtInRules, err := checks.LoadBuiltInRules()
+		if !flagBuiltin && flagRulesDir == "" {
+			return fmt.Errorf("--builtin=false needs --rules <dir>")
+		}
+		packs, builtInCount, err := loadRulePacks(flagBuiltin, flagRulesDir)
 		if err != nil {
-			return fmt.Errorf("load built-in rules: %w", err)
+			return err
 		}
-
-		var extraRules []checks.Rule
-		if flagRulesDir != "" {
-			extraRules, err = checks.LoadRulesFromDir(flagRulesDir)
-			if err != nil {
-				return fmt.Errorf("load extra rules from %q: %w", flagRulesDir, err)
+		for _, p := range packs {
+			for _, w := range p.Warnings {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", w)
 			}
 		}
+		allRules, err := rules.Merge(packs...)
+		if err != nil {
+			return fmt.Errorf("load rules:\n%w", err)
+		}
+		externalCount := len(allRules) - builtInCount
 
-		allRules := append([]checks.Rule{}, builtInRules...)
-		allRules = append(allRules, extraRules...)
+		allRules, tailoring, err := tailorRules(allRules, flagProfile)
+		if err != nil {
+			return err
+		}
+
+		var waivers []rules.WaiverEntry
+		if flagWaivers != "" {
+			if waivers, err = rules.LoadWaivers(flagWaivers); err != nil {
+				return fmt.Errorf("load waivers:\n%w", err)
+			}
+		}
 
-		activeRules := checks.FilterForMode(flagAll, flagCIS, flagPE, allRules)
+		activeRules, bench, err := flagSelect.apply(checks.FilterForMode(flagAll, flagCIS, flagPE, allRules))
+		if err != nil {
+			return err
+		}
 		if len(activeRules) == 0 {
 			return fmt.Errorf("no rules selected to run (check your flags)")
 		}
 
-		fmt.Printf("Loaded %d built-in rules", len(builtInRules))
-		if len(extraRules) > 0 {
-			fmt.Printf(" + %d external rules", len(extraRules))
+		fmt.Printf("Loaded %d built-in rules", builtInCount)
+		if externalCount > 0 {
+			fmt.Printf(" + %d external rules", externalCount)
 		}
 		fmt.Println(".")
+		if tailoring != nil {
+			fmt.Printf("Profile %q (%s): %d override(s) applied.\n", tailoring.Profile, tailoring.File, len(tailoring.Overrides))
+		}
 
 		// 3) concurrency & timeout defaults
 		if flagJobs <= 0 {
@@ -95,6 +145,12 @@ var scanCmd = &cobra.Command{
 			flagTimeout = time.Minute
 		}
 
+		// Ctrl-C / SIGTERM cancels the scan: running collectors are stopped,
+		// unfinished rules become "error: cancelled" and a partial report is
+		// still written.
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
 		bar := progressbar.NewOptions(
 			len(activeRules),
 			progressbar.OptionSetDescription("Running checks"),
@@ -102,6 +158,10 @@ var scanCmd = &cobra.Command{
 			progressbar.OptionClearOnFinish(),
 		)
 
+		// one fact store per scan: workers share collected facts, file reads
+		// and command output instead of repeating them per rule
+		store := checks.NewFactStore()
+
 		results := make([]checks.CheckResult, len(activeRules))
 		jobs := make(chan int)
 		var wg sync.WaitGroup
@@ -112,7 +172,7 @@ var scanCmd = &cobra.Command{
 				defer wg.Done()
 				for idx := range jobs {
 					rule := activeRules[idx]
-					res := checks.EvaluateWithTimeout(rule, flagTimeout)
+					res := checks.EvaluateWithTimeout(ctx, store, rule, flagTimeout)
 					results[idx] = res
 					_ = bar.Add(1)
 				}
@@ -129,7 +189,30 @@ var scanCmd = &cobra.Command{
 		wg.Wait()
 		_ = bar.Finish()
 
+		hostname, _ := os.Hostname()
+		rules.ApplyWaivers(results, waivers, hostname, time.Now())
+		var coverage *benchmark.Coverage
+		if bench != nil {
+			coverage = benchmark.Compute(bench, results, flagSelect.CISLevel, flagSelect.CISProfile)
+		}
+
+		// a second Ctrl-C while reports are written terminates immediately
+		interrupted := ctx.Err() != nil
+		stop()
+
 		fmt.Printf("Evaluated %d rules.\n", len(activeRules))
+		if interrupted {
+			cancelled := 0
+			for _, r := range results {
+				if r.Status == "error" && r.Observed.String() == "cancelled" {
+					cancelled++
+				}
+			}
+			fmt.Printf("%sScan interrupted:%s %d rule(s) cancelled; reports will be partial.\n", colorYellow, colorReset, cancelled)
+		}
+		if flagVerbose {
+			fmt.Printf("Fact cache: %s\n", store.Stats())
+		}
 		fmt.Printf("Starting scan… (all=%v, cis=%v, pe=%v, timeout=%s, jobs=%d)\n\n",
 			flagAll, flagCIS, flagPE, flagTimeout, flagJobs)
 
@@ -180,8 +263,14 @@ var scanCmd = &cobra.Command{
 				if strings.TrimSpace(exp) == "" {
 					exp = "<see rule>"
 				}
-				fmt.Printf("  • [%s] %s — observed=%q → expected=%q\n",
-					f.Category, f.Title, f.Observed, exp)
+				if len(f.Offenders) > 0 {
+					fmt.Printf("  • [%s] %s — %d offender(s) → expected=%q\n",
+						f.Category, f.Title, len(f.Offenders), exp)
+					printOffenders(f.Offenders)
+				} else {
+					fmt.Printf("  • [%s] %s — observed=%q → expected=%q\n",
+						f.Category, f.Title, f.Observed, exp)
+				}
 
 				if f.Remediation != "" {
 					fmt.Printf("    Remediation: %s\n", f.Remediation)
@@ -194,25 +283,32 @@ var scanCmd = &cobra.Command{
 			fmt.Println("No failed checks 🎉")
 		}
 
-		// 6) auto-fix / interactive mode
-		if flagEmitFix != "" {
-			if err := writeFixScript(flagEmitFix, results); err != nil {
-				return err
-			}
-			fmt.Printf("Fix script written to: %s\n", flagEmitFix)
-		} else if flagInteractive && len(failed) > 0 {
-			if err := runInteractiveHardening(results); err != nil {
-				return err
+		printExcluded(results, "na", "Not applicable")
+		printExcluded(results, "insufficient_privileges", "Insufficient privileges")
+		printExcluded(results, "waived", "Waived")
+		printExpiredWaivers(results)
+		printCoverage(coverage)
+
+		// 6) auto-fix / interactive mode (skipped for an interrupted scan)
+		if !interrupted {
+			if flagEmitFix != "" {
+				if err := writeFixScript(flagEmitFix, results); err != nil {
+					return err
+				}
+				fmt.Printf("Fix script written to: %s\n", flagEmitFix)
+			} else if flagInteractive && len(failed) > 0 {
+				if err := runInteractiveHardening(results); err != nil {
+					return err
+				}
 			}
 		}
 
 		// 7) reports (JSON / HTML)
-		hostname, _ := os.Hostname()
 		tstamp := time.Now().UTC().Format(time.RFC3339)
 		version, commit, buildDate := buildVersion()
 
 		if flagJSON != "" {
-			if err := jsonreport.Write(flagJSON, results, hostname, tstamp, version, commit, buildDate); err != nil {
+			if err := jsonreport.Write(flagJSON, results, tailoring, coverage, hostname, tstamp, version, commit, buildDate); err != nil {
 				return fmt.Errorf("write JSON report: %w", err)
 			}
 			fmt.Printf("JSON written to: %s\n", flagJSON)
@@ -226,11 +322,13 @@ var scanCmd = &cobra.Command{
 				tstamp,
 				scores,
 				results,
-				len(builtInRules),
-				len(extraRules),
+				builtInCount,
+				externalCount,
 				flagJobs,
 				flagTimeout.String(),
 				isRoot,
+				tailoring,
+				coverage,
 				version,
 				commit,
 				buildDate,
@@ -240,6 +338,10 @@ var scanCmd = &cobra.Command{
 			fmt.Printf("HTML written to: %s\n", flagHTML)
 		}
 
+		if interrupted {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("scan interrupted")
+		}
 		return nil
 	},
 }
@@ -258,15 +360,65 @@ func init() {
 	scanCmd.Flags().StringVar(&flagJSON, "json", "", "Write results to JSON file")
 	scanCmd.Flags().StringVar(&flagHTML, "html", "", "Write report to HTML file")
 	scanCmd.Flags().StringVar(&flagRulesDir, "rules", "", "Directory with extra rule files (*.yml, *.yaml)")
+	scanCmd.Flags().BoolVar(&flagBuiltin, "builtin", true, "Include the built-in rule pack (--builtin=false runs only --rules)")
+	scanCmd.Flags().StringVar(&flagProfile, "profile", "", "Tailoring profile that disables, re-weights or overrides rules")
+	scanCmd.Flags().StringVar(&flagWaivers, "waivers", "", "Waivers file of accepted risks (owner, ticket, expiry)")
+	scanCmd.Flags().StringSliceVar(&flagAllowExec, "allow-exec", nil, "Absolute path of a binary rule pack facts may run (repeatable)")
+	scanCmd.Flags().StringVar(&flagGTFOBins, "gtfobins", "", "GTFOBins dataset file replacing the built-in one for the recon checks")
+	addSelectionFlags(scanCmd, &flagSelect)
 	scanCmd.Flags().StringVar(&flagEmitFix, "emit-fix", "", "Write remediation script to this path (no execution)")
 	scanCmd.Flags().BoolVar(&flagInteractive, "interactive", false, "Interactive mode to review and generate a fix.sh script (experimental)")
 
 	// Remote flags
-	scanCmd.Flags().StringVar(&flagSSHHost, "ssh-host", "", "Remote host to scan via SSH (requires redcheck on remote PATH)")
+	scanCmd.Flags().StringVar(&flagSSHHost, "ssh-host", "", "Remote host to scan via SSH (requires redcheck on remote PATH; give --rules, --profile, --waivers and --gtfobins as remote:<path> on that host)")
 	scanCmd.Flags().StringVar(&flagSSHUser, "ssh-user", "", "SSH user for remote scan (default: current user)")
 	scanCmd.Flags().StringVar(&flagSSHKey, "ssh-key", "", "SSH private key for remote scan (optional)")
 }
 
+// loadRulePacks loads the built-in pack (if builtin) followed by the packs
+// under dir (the --rules flag), registers the script facts they define
+// and returns how many rules are built in.
+func loadRulePacks(builtin bool, dir string) ([]*rules.Pack, int, error) {
+	var packs []*rules.Pack
+	builtInCount := 0
+	if builtin {
+		p, err := rules.LoadBuiltIn()
+		if err != nil {
+			return nil, 0, fmt.Errorf("load built-in rules:\n%w", err)
+		}
+		packs = append(packs, p)
+		builtInCount = len(p.Rules)
+	}
+	if dir != "" {
+		extra, err := rules.LoadDir(dir)
+		if err != nil {
+			return nil, 0, fmt.Errorf("load extra rules from %q:\n%w", dir, err)
+		}
+		packs = append(packs, extra...)
+	}
+	if err := rules.RegisterFacts(packs...); err != nil {
+		return nil, 0, fmt.Errorf("load facts:\n%w", err)
+	}
+	return packs, builtInCount, nil
+}
+
+// tailorRules applies the --profile file (if any) and expands ${var}
+// placeholders in the rules.
+func tailorRules(all []checks.Rule, profilePath string) ([]checks.Rule, *rules.Tailoring, error) {
+	var profile *rules.Profile
+	if profilePath != "" {
+		var err error
+		if profile, err = rules.LoadProfile(profilePath); err != nil {
+			return nil, nil, fmt.Errorf("load profile:\n%w", err)
+		}
+	}
+	tailored, t, err := rules.Tailor(all, profile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tailor rules:\n%w", err)
+	}
+	return tailored, t, nil
+}
+
 // ── scoring helpers ────────────────────────────────────────────────────────────
 
 const (
@@ -313,6 +465,78 @@ func printScoreSummary(scores scoring.Scores) {
 	fmt.Println()
 }
 
+// maxOffenders caps the offenders listed per finding on the terminal; the
+// reports list all of them.
+const maxOffenders = 10
+
+func printOffenders(offenders []string) {
+	for i, o := range offenders {
+		if i == maxOffenders {
+			fmt.Printf("      … and %d more (see the JSON/HTML report)\n", len(offenders)-maxOffenders)
+			break
+		}
+		fmt.Printf("      - %s\n", o)
+	}
+}
+
+// printExcluded lists the rules with a status that is left out of the score
+// ("na", "insufficient_privileges", "waived") together with the reason.
+func printExcluded(results []checks.CheckResult, status, heading string) {
+	var excluded []checks.CheckResult
+	for _, r := range results {
+		if r.Status == status {
+			excluded = append(excluded, r)
+		}
+	}
+	if len(excluded) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("%s (%d, excluded from score):\n", heading, len(excluded))
+	for _, r := range excluded {
+		fmt.Printf("  • [%s] %s — %s\n", r.ID, r.Title, r.Reason)
+	}
+}
+
+// printExpiredWaivers calls out failures whose waiver has run out, so that
+// a lapsed exception does not go unnoticed.
+func printExpiredWaivers(results []checks.CheckResult) {
+	var expired []checks.CheckResult
+	for _, r := range results {
+		if r.Waiver != nil && r.Waiver.Expired {
+			expired = append(expired, r)
+		}
+	}
+	if len(expired) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("%sExpired waivers (%d, counted as failures):%s\n", colorYellow, len(expired), colorReset)
+	for _, r := range expired {
+		w := r.Waiver
+		fmt.Printf("  • [%s] %s — expired %s (owner %s, %s) at %s\n", r.ID, r.Title, w.Expires, w.Owner, w.Ticket, w.Source)
+	}
+}
+
+// printCoverage summarises the --benchmark coverage: how many controls in
+// scope RedCheck checked and how they came out.
+func printCoverage(c *benchmark.Coverage) {
+	if c == nil {
+		return
+	}
+	scope := "all levels"
+	if c.Level > 0 {
+		scope = fmt.Sprintf("Level %d", c.Level)
+	}
+	if c.Profile != "" {
+		scope += ", " + c.Profile
+	}
+	fmt.Println()
+	fmt.Printf("Benchmark %s %s (%s): %d controls\n", c.Benchmark, c.Version, scope, c.Controls)
+	fmt.Printf("  implemented=%d  passed=%d  failed=%d  not automated=%d\n",
+		c.Implemented, c.Passed, c.Failed, c.NotAutomated)
+}
+
 func severityWeight(s string) int {
 	switch strings.ToLower(s) {
 	case "critical":
@@ -386,6 +610,19 @@ func runInteractiveHardening(results []checks.CheckResult) error {
 
 // ── remote scan via SSH ───────────────────────────────────────────────────────
 
+// remotePrefix marks an input path as one on the host a remote scan runs on.
+const remotePrefix = "remote:"
+
+// remoteInputPath returns the path a remote scan passes for an input file
+// flag. Only "remote:<path>" is accepted: a plain path names a local file,
+// which the remote redcheck cannot read.
+func remoteInputPath(flag, value string) (string, error) {
+	if p, ok := strings.CutPrefix(value, remotePrefix); ok && p != "" {
+		return p, nil
+	}
+	return "", fmt.Errorf("--%s %s: a remote scan reads its input files on %s; copy the file there and pass --%s %s<path>", flag, value, flagSSHHost, flag, remotePrefix)
+}
+
 func runRemoteScan() error {
 	if flagSSHHost == "" {
 		return fmt.Errorf("ssh-host is required for remote scan")
@@ -422,9 +659,30 @@ func runRemoteScan() error {
 	if flagHTML != "" {
 		remoteArgs = append(remoteArgs, "--html", flagHTML)
 	}
-	if flagRulesDir != "" {
-		remoteArgs = append(remoteArgs, "--rules", flagRulesDir)
+	// input files are read by the remote redcheck, so they must already
+	// be on the scanned host
+	for _, f := range []struct{ name, value string }{
+		{"rules", flagRulesDir},
+		{"profile", flagProfile},
+		{"waivers", flagWaivers},
+		{"gtfobins", flagGTFOBins},
+	} {
+		if f.value == "" {
+			continue
+		}
+		p, err := remoteInputPath(f.name, f.value)
+		if err != nil {
+			return err
+		}
+		remoteArgs = append(remoteArgs, "--"+f.name, p)
+	}
+	if !flagBuiltin {
+		remoteArgs = append(remoteArgs, "--builtin=false")
+	}
+	for _, c := range flagAllowExec {
+		remoteArgs = append(remoteArgs, "--allow-exec", c)
 	}
+	remoteArgs = append(remoteArgs, flagSelect.args()...)
 	if flagEmitFix != "" {
 		remoteArgs = append(remoteArgs, "--emit-fix", flagEmitFix)
 	}
@@ -439,6 +697,12 @@ func runRemoteScan() error {
 	}
 
 	fmt.Printf("%s[remote]%s Connecting to %s\n", colorCyan, colorReset, dest)
+	// ssh hands the remote shell one command line: quote each argument so
+	// values such as "ssh and not extra" arrive intact
+	for i, a := range remoteArgs {
+		remoteArgs[i] = shellQuote(a)
+	}
+
 	fmt.Printf("%s[remote]%s Running: %s\n\n", colorCyan, colorReset, strings.Join(remoteArgs, " "))
 
 	// Build ssh command: ssh [-i key] user@host redcheck scan ...
@@ -456,3 +720,11 @@ func runRemoteScan() error {
 
 	return cmd.Run()
 }
+
+// shellQuote single-quotes s for a POSIX shell unless it is plainly safe.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,@+") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/benchmark"
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+)
+
+// selection holds the rule selection flags shared by scan and rules list.
+type selection struct {
+	checks.Selector
+	benchmark string
+}
+
+// addSelectionFlags registers the rule selection flags on c, bound to sel.
+func addSelectionFlags(c *cobra.Command, sel *selection) {
+	c.Flags().StringVar(&sel.Tags, "tags", "", "Only rules matching this tag expression (e.g. 'ssh and not extra')")
+	c.Flags().StringSliceVar(&sel.ExcludeTags, "exclude-tags", nil, "Skip rules carrying any of these tags")
+	c.Flags().StringSliceVar(&sel.IDs, "id", nil, "Only rules whose ID matches one of these globs (e.g. 'CIS-5.*')")
+	c.Flags().StringSliceVar(&sel.ExcludeIDs, "exclude-id", nil, "Skip rules whose ID matches one of these globs")
+	c.Flags().StringSliceVar(&sel.Categories, "category", nil, "Only rules in these categories")
+	c.Flags().StringVar(&sel.MinSeverity, "min-severity", "", "Only rules of at least this severity (Low, Medium, High, Critical)")
+	c.Flags().StringVar(&sel.benchmark, "benchmark", "", "Only rules implementing this benchmark's controls (e.g. cis-rocky9)")
+	c.Flags().IntVar(&sel.CISLevel, "level", 0, "With --benchmark: CIS level 1 or 2 (level 2 includes level 1)")
+	// --profile is the tailoring profile, hence --cis-profile
+	c.Flags().StringVar(&sel.CISProfile, "cis-profile", "", "With --benchmark: CIS profile server or workstation")
+}
+
+// apply validates the flags and narrows rules. It returns the --benchmark
+// catalogue, if any, for the coverage report.
+func (sel *selection) apply(rules []checks.Rule) ([]checks.Rule, *benchmark.Benchmark, error) {
+	if (sel.CISLevel != 0 || sel.CISProfile != "") && sel.benchmark == "" {
+		return nil, nil, fmt.Errorf("--level and --cis-profile need --benchmark (built in: %s)", strings.Join(benchmark.Names(), ", "))
+	}
+	if err := sel.Compile(); err != nil {
+		return nil, nil, err
+	}
+	selected := sel.Filter(rules)
+	if sel.benchmark == "" {
+		return selected, nil, nil
+	}
+	b, err := benchmark.Load(sel.benchmark)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the Selector already applied --level and --cis-profile
+	var out []checks.Rule
+	for _, r := range selected {
+		if b.Implements(r) {
+			out = append(out, r)
+		}
+	}
+	return out, b, nil
+}
+
+// args renders the selection back into flags, so a remote scan selects
+// exactly the rules a local one would.
+func (sel *selection) args() []string {
+	var args []string
+	if sel.Tags != "" {
+		args = append(args, "--tags", sel.Tags)
+	}
+	for _, f := range []struct {
+		name string
+		vals []string
+	}{
+		{"--exclude-tags", sel.ExcludeTags},
+		{"--id", sel.IDs},
+		{"--exclude-id", sel.ExcludeIDs},
+		{"--category", sel.Categories},
+	} {
+		if len(f.vals) > 0 {
+			args = append(args, f.name, strings.Join(f.vals, ","))
+		}
+	}
+	if sel.MinSeverity != "" {
+		args = append(args, "--min-severity", sel.MinSeverity)
+	}
+	if sel.benchmark != "" {
+		args = append(args, "--benchmark", sel.benchmark)
+	}
+	if sel.CISLevel != 0 {
+		args = append(args, "--level", fmt.Sprint(sel.CISLevel))
+	}
+	if sel.CISProfile != "" {
+		args = append(args, "--cis-profile", sel.CISProfile)
+	}
+	return args
+}
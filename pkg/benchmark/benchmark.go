@@ -0,0 +1,230 @@
+// Package benchmark holds the benchmarks RedCheck maps rules onto (the
+// control catalogue with CIS levels and profiles) and computes a scan's
+// coverage of one.
+package benchmark
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+	"github.com/Shunsuiky0raku/redcheck/pkg/rules"
+)
+
+// KindBenchmark is the document kind of a control catalogue.
+const KindBenchmark = "Benchmark"
+
+//go:embed *.yaml
+var builtin embed.FS
+
+// Benchmark is a control catalogue.
+type Benchmark struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   rules.Metadata `yaml:"metadata"`
+	Controls   []Control      `yaml:"controls"`
+}
+
+// Control is one benchmark recommendation.
+type Control struct {
+	ID       string   `yaml:"id"`
+	Title    string   `yaml:"title"`
+	Level    int      `yaml:"level"`   // 1 or 2
+	Profiles []string `yaml:"profile"` // server, workstation; empty = both
+}
+
+// InScope reports whether c belongs to the given level (0 = any) and
+// profile ("" = any).
+func (c Control) InScope(level int, profile string) bool {
+	if level > 0 && c.Level > level {
+		return false
+	}
+	if profile == "" || len(c.Profiles) == 0 {
+		return true
+	}
+	for _, p := range c.Profiles {
+		if strings.EqualFold(p, profile) {
+			return true
+		}
+	}
+	return false
+}
+
+// Names lists the embedded benchmarks.
+func Names() []string {
+	entries, _ := fs.ReadDir(builtin, ".")
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".yaml"))
+	}
+	return names
+}
+
+// Load returns the embedded benchmark called name, or reads name as a
+// catalogue file.
+func Load(name string) (*Benchmark, error) {
+	data, err := builtin.ReadFile(name + ".yaml")
+	if errors.Is(err, fs.ErrNotExist) {
+		if data, err = os.ReadFile(name); err != nil {
+			return nil, fmt.Errorf("unknown benchmark %q (built in: %s)", name, strings.Join(Names(), ", "))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var b Benchmark
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&b); err != nil {
+		return nil, fmt.Errorf("benchmark %s: %w", name, err)
+	}
+	if b.APIVersion != rules.APIVersion || b.Kind != KindBenchmark {
+		return nil, fmt.Errorf("benchmark %s: want apiVersion %s and kind %s, got %q/%q",
+			name, rules.APIVersion, KindBenchmark, b.APIVersion, b.Kind)
+	}
+	return &b, nil
+}
+
+// Control returns the control with the given ID, or nil.
+func (b *Benchmark) Control(id string) *Control {
+	for i := range b.Controls {
+		if b.Controls[i].ID == id {
+			return &b.Controls[i]
+		}
+	}
+	return nil
+}
+
+// Implements reports whether rule r implements one of b's controls.
+func (b *Benchmark) Implements(r checks.Rule) bool {
+	return b.Control(checks.CISControl(r.ID)) != nil
+}
+
+// Coverage summarises how a scan covered a benchmark's controls.
+type Coverage struct {
+	Benchmark    string            `json:"benchmark"`
+	Version      string            `json:"version"`
+	Level        int               `json:"level,omitempty"`
+	Profile      string            `json:"profile,omitempty"`
+	Controls     int               `json:"controls"`      // controls in scope
+	Implemented  int               `json:"implemented"`   // checked by at least one rule
+	Passed       int               `json:"passed"`        // every rule passed
+	Failed       int               `json:"failed"`        // at least one rule failed
+	NotAutomated int               `json:"not_automated"` // no rule checks the control
+	Details      []ControlCoverage `json:"details"`
+}
+
+// ControlCoverage is one control's outcome.
+type ControlCoverage struct {
+	ID     string   `json:"id"`
+	Title  string   `json:"title"`
+	Status string   `json:"status"` // worst rule status, or "not_automated"
+	Rules  []string `json:"rules,omitempty"`
+}
+
+// statusOrder ranks rule statuses for a control; the lowest wins.
+var statusOrder = map[string]int{
+	"fail": 0, "error": 1, "insufficient_privileges": 2, "waived": 3, "pass": 4, "na": 5,
+}
+
+// statusRank ranks an unknown status like "error": never as a failure,
+// never as a pass.
+func statusRank(status string) int {
+	if r, ok := statusOrder[status]; ok {
+		return r
+	}
+	return statusOrder["error"]
+}
+
+// Lint reports rules whose cis_level or cis_profile disagree with the
+// control they implement in b. Selection filters on the rule's copy and
+// coverage on the catalogue's, so the two must match.
+func Lint(b *Benchmark, rs []checks.Rule) []rules.Issue {
+	var issues []rules.Issue
+	for _, r := range rs {
+		c := b.Control(checks.CISControl(r.ID))
+		if c == nil {
+			continue
+		}
+		add := func(format string, args ...any) {
+			msg := fmt.Sprintf("%s: ", r.ID) + fmt.Sprintf(format, args...) + fmt.Sprintf(" (%s control %s)", b.Metadata.Name, c.ID)
+			issues = append(issues, rules.Issue{Diagnostic: rules.Diagnostic{Pos: r.Source, Msg: msg}, Level: rules.LevelError})
+		}
+		if r.CISLevel != c.Level {
+			add("cis_level %d, benchmark says level %d", r.CISLevel, c.Level)
+		}
+		if got, want := profileSet(r.CISProfiles), profileSet(c.Profiles); got != want {
+			add("cis_profile %s, benchmark says %s", got, want)
+		}
+	}
+	return issues
+}
+
+// profileSet renders a profile list for comparison; empty means both.
+func profileSet(ps []string) string {
+	if len(ps) == 0 {
+		return "[server workstation]"
+	}
+	norm := make([]string, len(ps))
+	for i, p := range ps {
+		norm[i] = strings.ToLower(p)
+	}
+	sort.Strings(norm)
+	if len(norm) == 2 && norm[0] == "server" && norm[1] == "workstation" {
+		return "[server workstation]"
+	}
+	return fmt.Sprint(norm)
+}
+
+// Compute maps results onto the controls of b in scope for level and
+// profile.
+func Compute(b *Benchmark, results []checks.CheckResult, level int, profile string) *Coverage {
+	byControl := map[string][]checks.CheckResult{}
+	for _, r := range results {
+		if ctl := checks.CISControl(r.ID); ctl != "" {
+			byControl[ctl] = append(byControl[ctl], r)
+		}
+	}
+
+	cov := &Coverage{
+		Benchmark: b.Metadata.Name,
+		Version:   b.Metadata.Version,
+		Level:     level,
+		Profile:   strings.ToLower(profile),
+	}
+	for _, c := range b.Controls {
+		if !c.InScope(level, profile) {
+			continue
+		}
+		cov.Controls++
+		cc := ControlCoverage{ID: c.ID, Title: c.Title, Status: "not_automated"}
+		res := byControl[c.ID]
+		if len(res) == 0 {
+			cov.NotAutomated++
+			cov.Details = append(cov.Details, cc)
+			continue
+		}
+		cov.Implemented++
+		sort.Slice(res, func(i, j int) bool { return statusRank(res[i].Status) < statusRank(res[j].Status) })
+		cc.Status = res[0].Status
+		for _, r := range res {
+			cc.Rules = append(cc.Rules, r.ID)
+		}
+		switch cc.Status {
+		case "pass":
+			cov.Passed++
+		case "fail":
+			cov.Failed++
+		}
+		cov.Details = append(cov.Details, cc)
+	}
+	return cov
+}
@@ -0,0 +1,208 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Condition is one node of a rule's condition tree.
+//
+// A leaf names a Fact and compares it with Expected / ExpectedAll /
+// Operator+Values exactly like a single-fact rule does. A branch combines
+// child conditions with all (AND), any (OR) or not. When a node sets more
+// than one of these, every part must hold.
+type Condition struct {
+	Fact        string   `yaml:"fact,omitempty"`
+	Expected    string   `yaml:"expected,omitempty"`
+	ExpectedAll []string `yaml:"expected_all,omitempty"`
+	Operator    string   `yaml:"operator,omitempty"`
+	Values      []string `yaml:"values,omitempty"`
+
+	All []Condition `yaml:"all,omitempty"`
+	Any []Condition `yaml:"any,omitempty"`
+	Not *Condition  `yaml:"not,omitempty"`
+}
+
+// IsComposite reports whether the condition uses all/any/not branches.
+func (c Condition) IsComposite() bool {
+	return len(c.All) > 0 || len(c.Any) > 0 || c.Not != nil
+}
+
+// Facts returns every fact referenced by the tree, in first-seen order.
+func (c Condition) Facts() []string {
+	var out []string
+	seen := map[string]bool{}
+	var walk func(Condition)
+	walk = func(n Condition) {
+		if n.Fact != "" && !seen[n.Fact] {
+			seen[n.Fact] = true
+			out = append(out, n.Fact)
+		}
+		for _, ch := range n.All {
+			walk(ch)
+		}
+		for _, ch := range n.Any {
+			walk(ch)
+		}
+		if n.Not != nil {
+			walk(*n.Not)
+		}
+	}
+	walk(c)
+	return out
+}
+
+// Validate reports malformed nodes in the tree (empty nodes, unknown
+// operators, bad regexes or numeric bounds) without collecting any fact.
+func (c Condition) Validate() []error {
+	var errs []error
+	var walk func(Condition)
+	walk = func(n Condition) {
+		if n.Fact == "" && !n.IsComposite() {
+			errs = append(errs, fmt.Errorf("empty condition (no fact, all, any or not)"))
+		}
+		if n.Fact != "" && n.Operator != "" {
+			if _, _, _, err := evalOperator(n.Operator, Value{}, n.Expected, n.Values); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", n.Fact, err))
+			}
+		}
+		for _, ch := range n.All {
+			walk(ch)
+		}
+		for _, ch := range n.Any {
+			walk(ch)
+		}
+		if n.Not != nil {
+			walk(*n.Not)
+		}
+	}
+	walk(c)
+	return errs
+}
+
+// conditionResult is the outcome of evaluating one node of the tree.
+type conditionResult struct {
+	ok        bool
+	desc      string   // expectation in words, e.g. "svc.firewalld_state: enabled_active"
+	offenders []string // elements of list/map facts that made it fail
+	err       error
+}
+
+// evalLeaf compares one observed value with the expectation of a leaf node.
+// A failed check on a list or map fact reports the elements the operator
+// singled out, if any.
+func evalLeaf(c Condition, observed Value) conditionResult {
+	var res conditionResult
+	switch {
+	case len(c.ExpectedAll) > 0:
+		// ALL-OF (expected_all): every token must appear in the observed
+		// value; what is missing is not an element, so no offenders
+		missing := evaluateAllOf(observed, c.ExpectedAll)
+		return conditionResult{ok: len(missing) == 0, desc: joinExpected(c.ExpectedAll)}
+	case c.Operator != "":
+		ok, desc, offenders, err := evalOperator(c.Operator, observed, c.Expected, c.Values)
+		res = conditionResult{ok: ok, desc: desc, offenders: offenders, err: err}
+	case c.Expected == "":
+		// No explicit expectation: "info" pass
+		return conditionResult{ok: true}
+	default:
+		res = conditionResult{ok: observed.String() == c.Expected, desc: c.Expected}
+	}
+	if res.ok || res.err != nil {
+		res.offenders = nil
+	}
+	return res
+}
+
+// evalCondition walks the tree, writing one trace line per node to trace so
+// the evidence shows which leg of a composite rule failed.
+func evalCondition(c Condition, facts map[string]Value, depth int, trace *strings.Builder) conditionResult {
+	indent := strings.Repeat("  ", depth)
+	var parts []conditionResult
+
+	if c.Fact != "" {
+		observed := facts[c.Fact]
+		leaf := evalLeaf(c, observed)
+		fmt.Fprintf(trace, "%s[%s] %s = %q (expected %s)\n",
+			indent, traceStatus(leaf), c.Fact, observed.String(), leaf.desc)
+		leaf.desc = c.Fact + ": " + leaf.desc
+		parts = append(parts, leaf)
+	}
+
+	if len(c.All) > 0 {
+		parts = append(parts, evalBranch("all", c.All, facts, depth, trace))
+	}
+	if len(c.Any) > 0 {
+		parts = append(parts, evalBranch("any", c.Any, facts, depth, trace))
+	}
+	if c.Not != nil {
+		var sub strings.Builder
+		inner := evalCondition(*c.Not, facts, depth+1, &sub)
+		res := conditionResult{ok: !inner.ok, desc: "not(" + inner.desc + ")", err: inner.err}
+		fmt.Fprintf(trace, "%s[%s] not\n", indent, traceStatus(res))
+		trace.WriteString(sub.String())
+		parts = append(parts, res)
+	}
+
+	switch len(parts) {
+	case 0:
+		return conditionResult{err: fmt.Errorf("empty condition (no fact, all, any or not)")}
+	case 1:
+		return parts[0]
+	}
+	return combine("all", parts)
+}
+
+// evalBranch evaluates an all/any list and records the branch in the trace.
+func evalBranch(kind string, children []Condition, facts map[string]Value, depth int, trace *strings.Builder) conditionResult {
+	var sub strings.Builder
+	results := make([]conditionResult, 0, len(children))
+	for _, ch := range children {
+		results = append(results, evalCondition(ch, facts, depth+1, &sub))
+	}
+	res := combine(kind, results)
+	fmt.Fprintf(trace, "%s[%s] %s\n", strings.Repeat("  ", depth), traceStatus(res), kind)
+	trace.WriteString(sub.String())
+	return res
+}
+
+// combine folds child results with AND ("all") or OR ("any"). The
+// offenders of every failed child are kept, without repeats.
+func combine(kind string, results []conditionResult) conditionResult {
+	out := conditionResult{ok: kind == "all"}
+	descs := make([]string, 0, len(results))
+	seen := map[string]bool{}
+	for _, r := range results {
+		descs = append(descs, r.desc)
+		if r.err != nil && out.err == nil {
+			out.err = r.err
+		}
+		for _, o := range r.offenders {
+			if !r.ok && !seen[o] {
+				seen[o] = true
+				out.offenders = append(out.offenders, o)
+			}
+		}
+		if kind == "all" {
+			out.ok = out.ok && r.ok
+		} else {
+			out.ok = out.ok || r.ok
+		}
+	}
+	out.desc = kind + "(" + strings.Join(descs, ", ") + ")"
+	if out.ok {
+		out.offenders = nil
+	}
+	return out
+}
+
+func traceStatus(r conditionResult) string {
+	switch {
+	case r.err != nil:
+		return "ERROR"
+	case r.ok:
+		return "pass"
+	default:
+		return "FAIL"
+	}
+}
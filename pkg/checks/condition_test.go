@@ -0,0 +1,38 @@
+package checks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEvalLeafOffenders(t *testing.T) {
+	uid0 := StringList([]string{"root", "toor", "admin"})
+	tests := []struct {
+		name      string
+		cond      Condition
+		ok        bool
+		offenders []string
+	}{
+		{"all_match", Condition{Operator: "all_match", Expected: "^root$"}, false, []string{"toor", "admin"}},
+		{"none_match", Condition{Operator: "none_match", Expected: "^t"}, false, []string{"toor"}},
+		{"not_in", Condition{Operator: "not_in", Values: []string{"admin", "guest"}}, false, []string{"admin"}},
+		{"in", Condition{Operator: "in", Expected: "root,toor"}, false, []string{"admin"}},
+		{"not_contains", Condition{Operator: "not_contains", Expected: "toor"}, false, []string{"toor"}},
+		{"empty", Condition{Operator: "empty"}, false, []string{"root", "toor", "admin"}},
+		{"count_ge too few", Condition{Operator: "count_ge", Expected: "5"}, false, nil},
+		{"count_le", Condition{Operator: "count <=", Expected: "1"}, false, nil},
+		{"eq", Condition{Operator: "eq", Expected: "root"}, false, nil},
+		{"exact", Condition{Expected: "root"}, false, nil},
+		{"passing all_match", Condition{Operator: "all_match", Expected: "."}, true, nil},
+	}
+	for _, tt := range tests {
+		res := evalLeaf(tt.cond, uid0)
+		if res.err != nil {
+			t.Errorf("%s: %v", tt.name, res.err)
+			continue
+		}
+		if res.ok != tt.ok || !reflect.DeepEqual(res.offenders, tt.offenders) {
+			t.Errorf("%s: ok=%v offenders=%q, want ok=%v offenders=%q", tt.name, res.ok, res.offenders, tt.ok, tt.offenders)
+		}
+	}
+}
@@ -1,4 +1,28 @@
 package checks
 
+import (
+	"os"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/execx"
+	"github.com/Shunsuiky0raku/redcheck/pkg/gtfobins"
+)
+
 // Set by cmd package (e.g., from --verbose flag)
 var Verbose bool
+
+// Privileged reports whether the scan runs as root. When false, rules marked
+// requires_root and facts refused with "permission denied" are reported as
+// "insufficient_privileges" rather than failures.
+var Privileged = os.Geteuid() == 0
+
+// Runner executes the external commands collectors need (systemctl, sysctl,
+// chage, ...). Replaceable so scans can run commands elsewhere.
+var Runner execx.Runner = execx.LocalRunner{}
+
+// AllowedCommands are the absolute binary paths rule packs may run for
+// script facts (--allow-exec). Anything else is refused.
+var AllowedCommands []string
+
+// GTFOBins is the dataset the recon.*_gtfobins facts match binaries
+// against (--gtfobins); nil means the one built into the binary.
+var GTFOBins *gtfobins.Dataset
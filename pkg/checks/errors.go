@@ -0,0 +1,29 @@
+package checks
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// CollectionError means a fact could not be collected: the file was
+// unreadable, the command failed, and so on. The rule's outcome is unknown,
+// so EvaluateRule reports it as "error" (or "insufficient_privileges")
+// instead of comparing an empty value and calling the host misconfigured.
+//
+// Collectors simply return an error from Collect; collectFact wraps it.
+type CollectionError struct {
+	Fact string
+	Err  error
+}
+
+func (e *CollectionError) Error() string {
+	return "collect " + e.Fact + ": " + e.Err.Error()
+}
+
+func (e *CollectionError) Unwrap() error { return e.Err }
+
+// PermissionDenied reports whether the collector was refused access, which
+// usually means the scan needs root.
+func (e *CollectionError) PermissionDenied() bool {
+	return errors.Is(e.Err, fs.ErrPermission)
+}
@@ -2,27 +2,41 @@ package checks
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
 // EvaluateWithTimeout runs a single rule with a timeout and dynamic fact collection.
-func EvaluateWithTimeout(rule Rule, timeout time.Duration) CheckResult {
-	// Per-rule timeout guard (mainly for slower fact collectors / future exec checks)
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+// Facts are collected through store, which the caller shares between rules of
+// the same scan (nil disables caching). Cancelling parent (e.g. on Ctrl-C)
+// stops collectors and marks the rule as cancelled.
+func EvaluateWithTimeout(parent context.Context, store *FactStore, rule Rule, timeout time.Duration) CheckResult {
+	if parent.Err() != nil {
+		return CancelledResult(rule)
+	}
+
+	// Per-rule timeout guard: collectors receive ctx and stop when it expires
+	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
 
 	resultCh := make(chan CheckResult, 1)
 
 	go func() {
 		// 1) Collect facts for THIS rule (fact-centric engine)
-		facts, evidence := gatherFactsForRule(rule, timeout)
+		facts, errs, evidence := gatherFactsForRule(ctx, store, rule, timeout)
 
 		// 2) Evaluate rule against collected facts
-		res := EvaluateRule(rule, facts)
+		res := EvaluateRule(rule, facts, errs)
 
 		// 3) Attach evidence if verbose mode is enabled
-		if Verbose && evidence != "" && res.Evidence == "" {
-			res.Evidence = evidence
+		if Verbose && evidence != "" {
+			if res.Evidence == "" {
+				res.Evidence = evidence
+			} else {
+				res.Evidence += "\n" + evidence
+			}
 		}
 
 		select {
@@ -34,24 +48,51 @@ func EvaluateWithTimeout(rule Rule, timeout time.Duration) CheckResult {
 
 	select {
 	case <-ctx.Done():
-		// Hard timeout hit – mark as error
-		return CheckResult{
-			ID:       rule.ID,
-			Title:    rule.Title,
-			Category: rule.Category,
-			Status:   "error",
-			Observed: "timeout",
-			Expected: rule.Expected,
-			Severity: rule.Severity,
+		if parent.Err() != nil {
+			return CancelledResult(rule)
 		}
+		// Hard timeout hit – mark as error
+		result := baseResult(rule)
+		result.Status = "error"
+		result.Observed = StringValue("timeout")
+		return result
 	case r := <-resultCh:
 		return r
 	}
 }
 
+// CancelledResult is the result recorded for a rule that did not finish
+// because the scan was interrupted.
+func CancelledResult(rule Rule) CheckResult {
+	result := baseResult(rule)
+	result.Status = "error"
+	result.Observed = StringValue("cancelled")
+	return result
+}
+
+// baseResult carries the rule's identity and documentation into a result.
+func baseResult(rule Rule) CheckResult {
+	return CheckResult{
+		ID:          rule.ID,
+		Title:       rule.Title,
+		Category:    rule.Category,
+		Severity:    rule.Severity,
+		Expected:    rule.Expected,
+		Description: rule.Description,
+		Rationale:   rule.Rationale,
+		References:  rule.References,
+		Mappings:    rule.Mappings,
+		Fix:         rule.Fix,
+	}
+}
+
 // MAIN EVALUATION LOGIC (pure comparison; facts are already resolved)
-func EvaluateRule(rule Rule, facts map[string]string) CheckResult {
-	observed := ""
+//
+// errs holds the collection error of every fact that could not be
+// collected; a rule depending on one is "error" (or "insufficient_privileges"
+// when access was denied to an unprivileged scan), never "fail".
+func EvaluateRule(rule Rule, facts map[string]Value, errs map[string]error) CheckResult {
+	var observed Value
 	if facts != nil {
 		observed = facts[rule.Fact]
 	}
@@ -62,42 +103,146 @@ func EvaluateRule(rule Rule, facts map[string]string) CheckResult {
 		filePath = rule.Files[0]
 	}
 
-	result := CheckResult{
-		ID:          rule.ID,
-		Title:       rule.Title,
-		Category:    rule.Category,
-		Severity:    rule.Severity,
-		Observed:    observed,
-		Expected:    rule.Expected,
-		Remediation: rule.Remediation,
-		FilePath:    filePath,
-		Tags:        rule.Tags,
-	}
-
-	// ALL-OF rules (expected_all)
-	if len(rule.ExpectedAll) > 0 {
-		missing := evaluateAllOf(observed, rule.ExpectedAll)
-		if len(missing) == 0 {
-			result.Status = "pass"
-		} else {
-			result.Status = "fail"
-			// For ALL-OF rules, Expected is a comma-joined list
-			result.Expected = joinExpected(rule.ExpectedAll)
+	result := baseResult(rule)
+	result.Observed = observed
+	result.Remediation = rule.Remediation
+	result.FilePath = filePath
+	result.Tags = rule.Tags
+
+	// Applicability: a failed precondition makes the rule "na"
+	if rule.When != nil {
+		if collectionFailure(&result, rule.When.Facts(), errs) {
+			return result
 		}
+		var trace strings.Builder
+		when := evalCondition(*rule.When, facts, 0, &trace)
+		switch {
+		case when.err != nil:
+			result.Status = "error"
+			result.Evidence = fmt.Sprintf("rule %s: when: %v", rule.ID, when.err)
+			return result
+		case !when.ok:
+			result.Status = "na"
+			result.Observed = Value{}
+			observed := joinObserved(rule.When.Facts(), facts)
+			if names := rule.When.Facts(); len(names) == 1 {
+				observed = fmt.Sprintf("%q", facts[names[0]].String())
+			}
+			result.Reason = fmt.Sprintf("requires %s (observed %s)", when.desc, observed)
+			return result
+		}
+	}
+
+	if rule.RequiresRoot && !Privileged {
+		result.Status = "insufficient_privileges"
+		result.Observed = Value{}
+		result.Reason = "requires root; scan is not running as root"
 		return result
 	}
 
-	// Simple rule: if no explicit expectation, treat as "info" pass
-	if rule.Expected == "" {
-		result.Status = "pass"
+	cond := rule.Condition()
+	if collectionFailure(&result, cond.Facts(), errs) {
 		return result
 	}
 
-	if observed == rule.Expected {
-		result.Status = "pass"
+	var res conditionResult
+	if cond.IsComposite() {
+		// Composite rule: observed lists every fact, evidence holds the
+		// per-branch trace so the failing leg is visible.
+		var trace strings.Builder
+		res = evalCondition(cond, facts, 0, &trace)
+		result.Observed = StringValue(joinObserved(cond.Facts(), facts))
+		result.Evidence = strings.TrimRight(trace.String(), "\n")
 	} else {
+		res = evalLeaf(cond, observed)
+	}
+	if res.desc != "" {
+		result.Expected = res.desc
+	}
+
+	switch {
+	case res.err != nil:
+		result.Status = "error"
+		msg := fmt.Sprintf("rule %s: %v", rule.ID, res.err)
+		if result.Evidence != "" {
+			msg = result.Evidence + "\n" + msg
+		}
+		result.Evidence = msg
+	case res.ok:
+		result.Status = "pass"
+	default:
 		result.Status = "fail"
+		result.Offenders = res.offenders
 	}
 
 	return result
 }
+
+// joinObserved renders "fact=value" pairs for composite rules.
+func joinObserved(names []string, facts map[string]Value) string {
+	parts := make([]string, 0, len(names))
+	for _, n := range names {
+		parts = append(parts, n+"="+facts[n].String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// collectionFailure marks result "error" if any of names could not be
+// collected, with the causes as evidence. When every cause is a permission
+// denial on an unprivileged scan the status is "insufficient_privileges".
+func collectionFailure(result *CheckResult, names []string, errs map[string]error) bool {
+	var causes []string
+	denied := true
+	for _, n := range names {
+		err := errs[n]
+		if err == nil {
+			continue
+		}
+		causes = append(causes, err.Error())
+		var ce *CollectionError
+		if !errors.As(err, &ce) || !ce.PermissionDenied() {
+			denied = false
+		}
+	}
+	if len(causes) == 0 {
+		return false
+	}
+
+	result.Observed = Value{}
+	result.Evidence = strings.Join(causes, "\n")
+	if reason := contextFailure(names, errs); reason != "" {
+		// the rule's own deadline or the scan's cancellation, not the host
+		result.Status = "error"
+		result.Observed = StringValue(reason)
+		return true
+	}
+	if denied && !Privileged {
+		result.Status = "insufficient_privileges"
+		result.Reason = "permission denied; rerun as root"
+	} else {
+		result.Status = "error"
+	}
+	return true
+}
+
+// contextFailure returns "timeout" or "cancelled", the observed values
+// EvaluateWithTimeout records, when every failed fact among names stopped
+// because its context ended; "" otherwise.
+func contextFailure(names []string, errs map[string]error) string {
+	reason := ""
+	for _, n := range names {
+		err := errs[n]
+		switch {
+		case err == nil:
+		case errors.Is(err, context.DeadlineExceeded):
+			if reason == "" {
+				reason = "timeout"
+			}
+		case errors.Is(err, context.Canceled):
+			reason = "cancelled"
+		default:
+			return ""
+		}
+	}
+	return reason
+}
@@ -1,22 +1,23 @@
 package checks
 
 import (
-	"bufio"
-	"os"
+	"fmt"
 	"strconv"
 	"strings"
 )
 
-func readLoginDefs() map[string]string {
+func init() {
+	Register(NewValueCollector("useradd.inactive_ok", "bool: INACTIVE in /etc/default/useradd is 0..30", asBool(fromValue(UseraddInactiveOK))))
+}
+
+func readLoginDefs(req FactRequest) (map[string]string, error) {
 	out := map[string]string{}
-	f, err := os.Open("/etc/login.defs")
+	lines, err := req.ReadLines("/etc/login.defs")
 	if err != nil {
-		return out
+		return nil, fmt.Errorf("read /etc/login.defs: %w", err)
 	}
-	defer f.Close()
-	sc := bufio.NewScanner(f)
-	for sc.Scan() {
-		line := strings.TrimSpace(sc.Text())
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
@@ -27,60 +28,12 @@ func readLoginDefs() map[string]string {
 			out[k] = v
 		}
 	}
-	return out
-}
-
-func LoginDefsPassMaxDaysOK() (string, error) {
-	m := readLoginDefs()
-	v, ok := m["PASS_MAX_DAYS"]
-	if !ok {
-		return "false", nil
-	}
-	n, err := strconv.Atoi(v)
-	if err != nil {
-		return "false", nil
-	}
-	if n <= 365 && n > 0 {
-		return "true", nil
-	}
-	return "false", nil
-}
-
-func LoginDefsPassMinDaysOK() (string, error) {
-	m := readLoginDefs()
-	v, ok := m["PASS_MIN_DAYS"]
-	if !ok {
-		return "false", nil
-	}
-	n, err := strconv.Atoi(v)
-	if err != nil {
-		return "false", nil
-	}
-	if n >= 1 {
-		return "true", nil
-	}
-	return "false", nil
-}
-
-func LoginDefsPassWarnAgeOK() (string, error) {
-	m := readLoginDefs()
-	v, ok := m["PASS_WARN_AGE"]
-	if !ok {
-		return "false", nil
-	}
-	n, err := strconv.Atoi(v)
-	if err != nil {
-		return "false", nil
-	}
-	if n >= 7 {
-		return "true", nil
-	}
-	return "false", nil
+	return out, nil
 }
 
-func UseraddInactiveOK() (string, error) {
+func UseraddInactiveOK(req FactRequest) (string, error) {
 	// read defaults: /etc/default/useradd contains INACTIVE=<days> on Rocky/RHEL
-	b, err := os.ReadFile("/etc/default/useradd")
+	b, err := req.ReadFile("/etc/default/useradd")
 	if err != nil {
 		return "unknown", err
 	}
@@ -3,18 +3,27 @@ package checks
 import (
 	"bufio"
 	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 )
 
+func init() {
+	Register(NewValueCollector("acct.aging_policy_ok", "bool: human users have MAX<=365, MIN>=1, INACTIVE<=30 (chage -l)", asBool(func(req FactRequest) (string, string, error) {
+		v, offenders, err := AccountsAgingPolicyOK(req)
+		if offenders != "" {
+			offenders = "offenders: " + offenders
+		}
+		return v, offenders, err
+	})))
+}
+
 // returns ("true"/"false" or "unknown"), observed: "user1[max=...;min=...;inactive=...], user2[...]"
-func AccountsAgingPolicyOK() (string, string, error) {
+func AccountsAgingPolicyOK(req FactRequest) (string, string, error) {
 	users := localHumanUsers()
 	var offenders []string
 
 	for _, u := range users {
-		max, min, inactive, err := chageInfo(u)
+		max, min, inactive, err := chageInfo(req, u)
 		if err != nil {
 			// if chage not present or error, report unknown once
 			return "unknown", "chage_error", err
@@ -58,9 +67,8 @@ func localHumanUsers() []string {
 	return out
 }
 
-func chageInfo(user string) (max, min, inactive int, err error) {
-	cmd := exec.Command("chage", "-l", user)
-	b, e := cmd.Output()
+func chageInfo(req FactRequest, user string) (max, min, inactive int, err error) {
+	out, _, e := req.Run("chage", "-l", user)
 	if e != nil {
 		return 0, 0, 0, e
 	}
@@ -68,7 +76,7 @@ func chageInfo(user string) (max, min, inactive int, err error) {
 	// "Maximum number of days between password change : 365"
 	// "Minimum number of days between password change : 1"
 	// "Number of days of inactivity before account is locked : 30"
-	s := string(b)
+	s := out
 	max = findIntAfter(s, "Maximum number of days between password change")
 	min = findIntAfter(s, "Minimum number of days between password change")
 	inactive = findIntAfter(s, "Number of days of inactivity before account is locked")
@@ -1,45 +1,64 @@
 package checks
 
-import (
-	"os"
-	"strings"
-)
+import "strings"
 
-func pamFile(path string) string {
-	b, err := os.ReadFile(path)
+func init() {
+	Register(NewValueCollector("pam.pwquality_present", "bool: pam_pwquality.so in system-auth/password-auth", asBool(fromValue(PamPwqualityPresent))))
+	Register(NewValueCollector("pam.pwhistory_present", "bool: pam_pwhistory.so in system-auth/password-auth", asBool(fromValue(PamPwhistoryPresent))))
+	Register(NewValueCollector("pam.faillock_present", "bool: pam_faillock.so in system-auth/password-auth", asBool(fromValue(PamFaillockPresent))))
+	Register(NewValueCollector("pam.pwquality_args", "map: key=value arguments of pam_pwquality.so", pamArgsCollector(PamPwqualityArgs)))
+	Register(NewValueCollector("pam.pwhistory_args", "map: key=value arguments of pam_pwhistory.so", pamArgsCollector(PamPwhistoryArgs)))
+	Register(NewValueCollector("pam.faillock_args", "map: key=value arguments of pam_faillock.so", pamArgsCollector(PamFaillockArgs)))
+}
+
+// pamArgsCollector returns a module's arguments as a map fact; it renders
+// as sorted "k=v,k=v".
+func pamArgsCollector(fn func(FactRequest) map[string]string) func(FactRequest) (Value, string, error) {
+	return func(req FactRequest) (Value, string, error) {
+		args := fn(req)
+		m := make(map[string]Value, len(args))
+		for k, v := range args {
+			m[k] = StringValue(v)
+		}
+		return MapValue(m), "", nil
+	}
+}
+
+func pamFile(req FactRequest, path string) string {
+	b, err := req.ReadFile(path)
 	if err != nil {
 		return ""
 	}
 	return strings.ToLower(string(b))
 }
 
-func pamAny(paths []string) string {
+func pamAny(req FactRequest, paths []string) string {
 	var all strings.Builder
 	for _, p := range paths {
-		all.WriteString(pamFile(p))
+		all.WriteString(pamFile(req, p))
 		all.WriteByte('\n')
 	}
 	return all.String()
 }
 
-func PamPwqualityPresent() (string, error) {
-	s := pamAny([]string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
+func PamPwqualityPresent(req FactRequest) (string, error) {
+	s := pamAny(req, []string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
 	if strings.Contains(s, "pam_pwquality.so") {
 		return "true", nil
 	}
 	return "false", nil
 }
 
-func PamPwhistoryPresent() (string, error) {
-	s := pamAny([]string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
+func PamPwhistoryPresent(req FactRequest) (string, error) {
+	s := pamAny(req, []string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
 	if strings.Contains(s, "pam_pwhistory.so") {
 		return "true", nil
 	}
 	return "false", nil
 }
 
-func PamFaillockPresent() (string, error) {
-	s := pamAny([]string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
+func PamFaillockPresent(req FactRequest) (string, error) {
+	s := pamAny(req, []string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
 	if strings.Contains(s, "pam_faillock.so") {
 		return "true", nil
 	}
@@ -47,16 +66,16 @@ func PamFaillockPresent() (string, error) {
 }
 
 // Very simple arg finders; we can harden later.
-func PamPwqualityArgs() map[string]string {
-	s := pamAny([]string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
+func PamPwqualityArgs(req FactRequest) map[string]string {
+	s := pamAny(req, []string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
 	return parsePamArgs(s, "pam_pwquality.so")
 }
-func PamPwhistoryArgs() map[string]string {
-	s := pamAny([]string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
+func PamPwhistoryArgs(req FactRequest) map[string]string {
+	s := pamAny(req, []string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
 	return parsePamArgs(s, "pam_pwhistory.so")
 }
-func PamFaillockArgs() map[string]string {
-	s := pamAny([]string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
+func PamFaillockArgs(req FactRequest) map[string]string {
+	s := pamAny(req, []string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
 	return parsePamArgs(s, "pam_faillock.so")
 }
 func parsePamArgs(all, module string) map[string]string {
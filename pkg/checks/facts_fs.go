@@ -6,6 +6,19 @@ import (
 	"strings"
 )
 
+func init() {
+	Register(NewCollector("mount.separate:", "yes/no: <path> is its own mount point", func(req FactRequest) (string, string, error) {
+		ok, err := hasSeparateMount(req.Param)
+		if err != nil {
+			return "", "", err
+		}
+		if ok {
+			return "yes", req.Param + " is a separate mount", nil
+		}
+		return "no", req.Param + " is not a separate mount", nil
+	}))
+}
+
 func hasSeparateMount(path string) (bool, error) {
 	f, err := os.Open("/proc/mounts")
 	if err != nil {
@@ -0,0 +1,225 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/gtfobins"
+)
+
+// GTFOBins facts: sudo grants, SUID/SGID files and file capabilities whose
+// binary the dataset lists for that context. Every hit names the technique.
+func init() {
+	Register(NewValueCollector("recon.sudo_gtfobins", "list: sudo grants of GTFOBins binaries as root, per local user, with the technique", factReconSudoGTFOBins))
+	Register(NewValueCollector("recon.suid_gtfobins", "list: SUID/SGID GTFOBins binaries in the system and /opt directories, with the technique", factReconSuidGTFOBins))
+	Register(NewValueCollector("recon.capabilities_gtfobins", "list: GTFOBins binaries with root-escalating file capabilities (getcap), with the technique", factReconCapabilitiesGTFOBins))
+}
+
+// gtfobinsRoots are the trees searched for SUID/SGID files and file
+// capabilities; symlinked roots (/bin -> usr/bin) are skipped.
+var gtfobinsRoots = []string{"/usr/bin", "/usr/sbin", "/usr/libexec", "/usr/local", "/bin", "/sbin", "/opt"}
+
+func gtfobinsData() (*gtfobins.Dataset, error) {
+	if GTFOBins != nil {
+		return GTFOBins, nil
+	}
+	return gtfobins.Builtin()
+}
+
+// factReconSudoGTFOBins lists, per local non-root account, the commands it
+// may run as root whose binary escapes to root through sudo. A later "!cmd"
+// grant takes the command away; grants of ALL are RC-1.3's business.
+func factReconSudoGTFOBins(req FactRequest) (Value, string, error) {
+	data, err := gtfobinsData()
+	if err != nil {
+		return Value{}, "", err
+	}
+	cfg, err := sudoersConfig(req)
+	if err != nil {
+		return Value{}, "", err
+	}
+	users, err := sudoUsers(req)
+	if err != nil {
+		return Value{}, "", err
+	}
+	var hits []string
+	for _, u := range users {
+		last := map[string]SudoersGrant{}
+		var order []string
+		for _, g := range cfg.RootGrants(u) {
+			if _, seen := last[g.Command]; !seen {
+				order = append(order, g.Command)
+			}
+			last[g.Command] = g
+		}
+		for _, cmd := range order {
+			g := last[cmd]
+			bin, _, _ := strings.Cut(cmd, " ")
+			if g.Negated || cmd == "ALL" {
+				continue
+			}
+			t, ok := data.Lookup(bin, gtfobins.Sudo)
+			if !ok {
+				continue
+			}
+			tag := ""
+			if cfg.NoPasswd(g, u) {
+				tag = "NOPASSWD: "
+			}
+			hits = append(hits, fmt.Sprintf("%s: %s%s -> %s (%s)", u.Name, tag, cmd, t, g.Pos()))
+		}
+	}
+	return StringList(hits), fmt.Sprintf("%d local account(s) and %d grant(s) checked against %s", len(users), len(cfg.Grants), data), nil
+}
+
+// factReconSuidGTFOBins walks gtfobinsRoots for SUID/SGID regular files
+// the dataset lists in the suid context. SGID-only files are reported too:
+// the same technique yields the owning group.
+func factReconSuidGTFOBins(req FactRequest) (Value, string, error) {
+	data, err := gtfobinsData()
+	if err != nil {
+		return Value{}, "", err
+	}
+	var hits []string
+	found := 0
+	for _, root := range gtfobinsRoots {
+		if info, err := os.Lstat(root); err != nil || !info.IsDir() {
+			continue
+		}
+		walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if ctxErr := req.Context().Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil || !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			mode := info.Mode()
+			if mode&(os.ModeSetuid|os.ModeSetgid) == 0 {
+				return nil
+			}
+			found++
+			t, ok := data.Lookup(path, gtfobins.SUID)
+			if !ok {
+				return nil
+			}
+			hits = append(hits, fmt.Sprintf("%s (%s) -> %s", path, setidOwner(path, mode), t))
+			return nil
+		})
+		if walkErr != nil {
+			return Value{}, "", fmt.Errorf("walk %s: %w", root, walkErr)
+		}
+	}
+	return StringList(hits), fmt.Sprintf("%d SUID/SGID file(s) under %s checked against %s", found, strings.Join(gtfobinsRoots, ", "), data), nil
+}
+
+// setidOwner describes the identity a set-id file runs as, e.g.
+// "suid root" or "sgid shadow".
+func setidOwner(path string, mode fs.FileMode) string {
+	st, err := statUnix(path)
+	if err != nil {
+		return "set-id"
+	}
+	var out []string
+	if mode&os.ModeSetuid != 0 {
+		name := strconv.FormatUint(uint64(st.Uid), 10)
+		if u, err := user.LookupId(name); err == nil {
+			name = u.Username
+		}
+		out = append(out, "suid "+name)
+	}
+	if mode&os.ModeSetgid != 0 {
+		name := strconv.FormatUint(uint64(st.Gid), 10)
+		if g, err := user.LookupGroupId(name); err == nil {
+			name = g.Name
+		}
+		out = append(out, "sgid "+name)
+	}
+	return strings.Join(out, ", ")
+}
+
+// escalatingCaps are the file capabilities that give a GTFOBins binary a
+// way to root: changing identity, bypassing file permissions, or
+// controlling the kernel or other processes. cap_net_bind_service and
+// the like give none.
+var escalatingCaps = map[string]bool{
+	"cap_setuid": true, "cap_setgid": true, "cap_setfcap": true, "cap_setpcap": true,
+	"cap_dac_override": true, "cap_dac_read_search": true, "cap_chown": true, "cap_fowner": true,
+	"cap_sys_admin": true, "cap_sys_ptrace": true, "cap_sys_module": true, "cap_sys_rawio": true,
+}
+
+// permittedCaps returns the capabilities a getcap clause list puts in the
+// permitted or effective set, e.g. "cap_setuid,cap_net_raw=ep" or
+// "= cap_setuid+ep". A clause without names ("=ep") grants all of them.
+func permittedCaps(caps string) []string {
+	var out []string
+	for _, clause := range strings.Fields(caps) {
+		i := strings.IndexAny(clause, "=+-")
+		if i < 0 || clause[i] == '-' || !strings.ContainsAny(clause[i+1:], "ep") {
+			continue // a lone "=" (older getcap) or capabilities dropped
+		}
+		if i == 0 {
+			return []string{"all"}
+		}
+		out = append(out, strings.Split(strings.ToLower(clause[:i]), ",")...)
+	}
+	return out
+}
+
+// factReconCapabilitiesGTFOBins runs getcap over gtfobinsRoots. Lines are
+// "path caps" (libcap >= 2.41) or "path = caps" (older). A listed binary
+// counts only when it holds one of escalatingCaps.
+func factReconCapabilitiesGTFOBins(req FactRequest) (Value, string, error) {
+	data, err := gtfobinsData()
+	if err != nil {
+		return Value{}, "", err
+	}
+	args := []string{"-r"}
+	for _, root := range gtfobinsRoots {
+		if info, err := os.Lstat(root); err == nil && info.IsDir() {
+			args = append(args, root)
+		}
+	}
+	out, errOut, err := req.Run("getcap", args...)
+	if errors.Is(err, exec.ErrNotFound) {
+		return Value{}, "", errors.New("getcap not available (install libcap)")
+	}
+	if err != nil && out == "" {
+		return Value{}, "", fmt.Errorf("getcap -r failed: %v (stderr: %s)", err, errOut)
+	}
+	var hits []string
+	found := 0
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		found++
+		path := fields[0]
+		var escalating []string
+		for _, c := range permittedCaps(strings.Join(fields[1:], " ")) {
+			if c == "all" || escalatingCaps[c] {
+				escalating = append(escalating, c)
+			}
+		}
+		if len(escalating) == 0 {
+			continue
+		}
+		t, ok := data.Lookup(path, gtfobins.Capabilities)
+		if !ok {
+			continue
+		}
+		hits = append(hits, fmt.Sprintf("%s (%s) -> %s", path, strings.Join(escalating, ","), t))
+	}
+	return StringList(hits), fmt.Sprintf("%d file(s) with capabilities checked against %s", found, data), nil
+}
@@ -0,0 +1,25 @@
+package checks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPermittedCaps(t *testing.T) {
+	tests := []struct {
+		caps string
+		want []string
+	}{
+		{"cap_net_bind_service=ep", []string{"cap_net_bind_service"}},
+		{"cap_setuid,cap_net_raw=ep", []string{"cap_setuid", "cap_net_raw"}},
+		{"= cap_setuid+ep", []string{"cap_setuid"}}, // libcap < 2.41
+		{"cap_dac_read_search=p cap_chown=i", []string{"cap_dac_read_search"}},
+		{"=ep", []string{"all"}},
+		{"cap_setuid=i", nil},
+	}
+	for _, tt := range tests {
+		if got := permittedCaps(tt.caps); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("permittedCaps(%q) = %q, want %q", tt.caps, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register(NewCollector("os.release:", "value of <KEY> in /etc/os-release (e.g. ID, ID_LIKE, VERSION_ID)", factOSRelease))
+	Register(NewCollector("os.version_major", "major part of VERSION_ID in /etc/os-release, e.g. 9", factOSVersionMajor))
+}
+
+// readOSRelease parses /etc/os-release into KEY -> unquoted value.
+func readOSRelease(req FactRequest) (map[string]string, error) {
+	lines, err := req.ReadLines("/etc/os-release")
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		out[strings.ToUpper(strings.TrimSpace(k))] = strings.Trim(strings.TrimSpace(v), `"'`)
+	}
+	return out, nil
+}
+
+func factOSRelease(req FactRequest) (string, string, error) {
+	rel, err := readOSRelease(req)
+	if err != nil {
+		return "", "", fmt.Errorf("read /etc/os-release: %w", err)
+	}
+	key := strings.ToUpper(req.Param)
+	val, ok := rel[key]
+	if !ok {
+		return "", fmt.Sprintf("%s not set in /etc/os-release", key), nil
+	}
+	return val, fmt.Sprintf("%s=%s (from /etc/os-release)", key, val), nil
+}
+
+func factOSVersionMajor(req FactRequest) (string, string, error) {
+	rel, err := readOSRelease(req)
+	if err != nil {
+		return "", "", fmt.Errorf("read /etc/os-release: %w", err)
+	}
+	ver := rel["VERSION_ID"]
+	major, _, _ := strings.Cut(ver, ".")
+	return major, fmt.Sprintf("VERSION_ID=%s", ver), nil
+}
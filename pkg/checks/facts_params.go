@@ -0,0 +1,168 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Parameterized facts of the form "family:param", e.g.
+// "sysctl:net.ipv4.ip_forward" or "pam.arg:pam_pwquality.so:minlen". Each
+// family is a generic collector, so new mount points, sshd directives or
+// files can be checked from YAML without Go changes.
+func init() {
+	Register(NewValueCollector("mount.options:", "list: mount options of <mountpoint> from /proc/mounts", func(req FactRequest) (Value, string, error) {
+		return factMountOptions(req, req.Param)
+	}))
+	Register(NewCollector("sshd.directive:", "effective value of sshd <Directive> (Includes, first value wins, sshd -T)", factSSHDirective))
+	Register(NewValueCollector("sshd.match_overrides:", "list: Match blocks that set sshd <Directive>", factSSHDMatchOverrides))
+	Register(NewCollector("sysctl:", "kernel parameter <key> via /proc/sys or sysctl -n", factSysctl))
+	Register(NewCollector("file.mode:", "octal permission bits of <path>, e.g. 0640", factFileMode))
+	Register(NewCollector("file.owner:", "owning user of <path>", factFileOwner))
+	Register(NewCollector("file.group:", "owning group of <path>", factFileGroup))
+	Register(NewCollector("file.exists:", "present/absent: <path> exists", factFileExists))
+	Register(NewCollector("login_defs:", "raw value of /etc/login.defs <KEY>", factLoginDefs))
+	Register(NewCollector("pam.arg:", "value of <module>:<arg> in system-auth/password-auth", fromEvidence(factPamArg)))
+}
+
+// factSSHDirective returns the effective value of an sshd directive, as
+// resolved by the sshd config model (Includes, first value wins, sshd -T).
+func factSSHDirective(req FactRequest) (string, string, error) {
+	cfg, err := sshdConfig(req)
+	if err != nil {
+		return "", "", err
+	}
+	val, ev := cfg.Value(req.Param)
+	return val, ev, nil
+}
+
+// factSSHDMatchOverrides lists the Match blocks that set a directive, as
+// "<criteria>: <value> (file:line)".
+func factSSHDMatchOverrides(req FactRequest) (Value, string, error) {
+	cfg, err := sshdConfig(req)
+	if err != nil {
+		return Value{}, "", err
+	}
+	var out []string
+	for _, m := range cfg.MatchSettings(req.Param) {
+		out = append(out, fmt.Sprintf("Match %s: %s (%s)", m.Match, m.Value, m.Pos()))
+	}
+	return StringList(out), fmt.Sprintf("%d Match block(s) set %s", len(out), req.Param), nil
+}
+
+// factSysctl reads a kernel parameter from /proc/sys, falling back to sysctl(8).
+func factSysctl(req FactRequest) (string, string, error) {
+	key := req.Param
+	procPath := filepath.Join("/proc/sys", strings.ReplaceAll(key, ".", "/"))
+	if data, err := req.ReadFile(procPath); err == nil {
+		val := strings.Join(strings.Fields(string(data)), " ")
+		return val, fmt.Sprintf("%s = %s (from %s)", key, val, procPath), nil
+	}
+
+	out, errOut, err := req.Run("sysctl", "-n", key)
+	if err != nil {
+		return "", "", fmt.Errorf("sysctl -n %s failed: %v (stderr: %s)", key, err, errOut)
+	}
+	return out, fmt.Sprintf("%s = %s (from sysctl)", key, out), nil
+}
+
+// factFileExists reports "absent" only when the path really does not exist;
+// any other stat failure (e.g. an unreadable parent directory) is an error.
+func factFileExists(req FactRequest) (string, string, error) {
+	_, err := os.Stat(req.Param)
+	switch {
+	case err == nil:
+		return "present", req.Param + " exists", nil
+	case errors.Is(err, fs.ErrNotExist):
+		return "absent", fmt.Sprintf("stat %s: %v", req.Param, err), nil
+	}
+	return "", "", fmt.Errorf("stat %s: %w", req.Param, err)
+}
+
+// factFileMode returns the permission bits of path in octal, e.g. "0640".
+func factFileMode(req FactRequest) (string, string, error) {
+	path := req.Param
+	st, err := statUnix(path)
+	if err != nil {
+		return "", "", err
+	}
+	mode := fmt.Sprintf("%04o", st.Mode&0o7777)
+	return mode, fmt.Sprintf("%s mode %s", path, mode), nil
+}
+
+// factFileOwner returns the owning user name of path (numeric UID if unknown).
+func factFileOwner(req FactRequest) (string, string, error) {
+	path := req.Param
+	st, err := statUnix(path)
+	if err != nil {
+		return "", "", err
+	}
+	uid := strconv.FormatUint(uint64(st.Uid), 10)
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	return name, fmt.Sprintf("%s owned by %s (uid %s)", path, name, uid), nil
+}
+
+// factFileGroup returns the owning group name of path (numeric GID if unknown).
+func factFileGroup(req FactRequest) (string, string, error) {
+	path := req.Param
+	st, err := statUnix(path)
+	if err != nil {
+		return "", "", err
+	}
+	gid := strconv.FormatUint(uint64(st.Gid), 10)
+	name := gid
+	if g, err := user.LookupGroupId(gid); err == nil {
+		name = g.Name
+	}
+	return name, fmt.Sprintf("%s group %s (gid %s)", path, name, gid), nil
+}
+
+func statUnix(path string) (*syscall.Stat_t, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: %w", path, err)
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil, fmt.Errorf("stat %s: no unix file attributes", path)
+	}
+	return st, nil
+}
+
+// factLoginDefs returns the raw value of a /etc/login.defs key.
+func factLoginDefs(req FactRequest) (string, string, error) {
+	defs, err := readLoginDefs(req)
+	if err != nil {
+		return "", "", err
+	}
+	key := strings.ToUpper(req.Param)
+	val, ok := defs[key]
+	if !ok {
+		return "", fmt.Sprintf("%s not set in /etc/login.defs", key), nil
+	}
+	return val, fmt.Sprintf("%s %s (from /etc/login.defs)", key, val), nil
+}
+
+// factPamArg resolves "module:arg" (e.g. "pam_pwquality.so:minlen") against
+// system-auth and password-auth.
+func factPamArg(req FactRequest) (string, string) {
+	module, arg, ok := strings.Cut(req.Param, ":")
+	if !ok || module == "" || arg == "" {
+		return "", fmt.Sprintf("pam.arg needs <module>:<arg>, got %q", req.Param)
+	}
+	s := pamAny(req, []string{"/etc/pam.d/system-auth", "/etc/pam.d/password-auth"})
+	val, ok := parsePamArgs(s, strings.ToLower(module))[strings.ToLower(arg)]
+	if !ok {
+		return "", fmt.Sprintf("%s has no %s= argument in system-auth/password-auth", module, arg)
+	}
+	return val, fmt.Sprintf("%s %s=%s", module, arg, val)
+}
@@ -1,177 +1,148 @@
 package checks
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"fmt"
 	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 )
 
 // gatherFactsForRule is the dynamic fact engine.
-// For now it's fact-centric: it looks at rule.Fact and collects just what is needed
-// for that rule. This keeps it simple and fast for your current rule set.
-func gatherFactsForRule(rule Rule, timeout time.Duration) (map[string]string, string) {
-	facts := make(map[string]string)
-
-	if rule.Fact == "" {
-		return facts, ""
+// It is fact-centric: it walks the rule's condition tree and collects just the
+// facts that rule references, keeping the scan simple and fast. A rule whose
+// "when" precondition does not hold, or that needs root on an unprivileged
+// scan, gets only its precondition facts. Collection errors are returned per
+// fact so EvaluateRule can tell "unknown" from "fail".
+func gatherFactsForRule(ctx context.Context, store *FactStore, rule Rule, timeout time.Duration) (map[string]Value, map[string]error, string) {
+	facts := make(map[string]Value)
+	errs := make(map[string]error)
+
+	if rule.When != nil {
+		for _, name := range rule.When.Facts() {
+			observed, _, err := collectFact(ctx, store, name, timeout)
+			facts[name] = observed
+			if err != nil {
+				errs[name] = err
+			}
+		}
+		var discard strings.Builder
+		if res := evalCondition(*rule.When, facts, 0, &discard); !res.ok || res.err != nil || len(errs) > 0 {
+			return facts, errs, ""
+		}
+	}
+	if rule.RequiresRoot && !Privileged {
+		return facts, errs, ""
 	}
 
-	var observed, evidence string
+	names := rule.Condition().Facts()
+	var evidence []string
+	for _, name := range names {
+		if ctx.Err() != nil {
+			break
+		}
+		observed, ev, err := collectFact(ctx, store, name, timeout)
+		facts[name] = observed
+		if err != nil {
+			// EvaluateRule puts the cause in the result's evidence
+			errs[name] = err
+			continue
+		}
+		if ev == "" {
+			continue
+		}
+		if len(names) > 1 {
+			ev = name + ": " + ev
+		}
+		evidence = append(evidence, ev)
+	}
+	return facts, errs, strings.Join(evidence, "\n")
+}
 
-	switch rule.Fact {
+func init() {
 	// ── SSH FACTS ──────────────────────────────────────────────────────────────
-	case "ssh.permit_root_login":
-		observed, evidence = factSSHPermitRootLogin()
-	case "ssh.x11_forwarding":
-		observed, evidence = factSSHX11Forwarding()
-	case "ssh.banner":
-		observed, evidence = factSSHBanner()
+	Register(NewCollector("ssh.permit_root_login", "effective PermitRootLogin of sshd (lower-cased)", factSSHPermitRootLogin))
+	Register(NewCollector("ssh.x11_forwarding", "effective X11Forwarding of sshd (lower-cased)", factSSHX11Forwarding))
+	Register(NewCollector("ssh.banner", "present/absent: Banner configured and the file exists", factSSHBanner))
 
 	// ── MOUNT OPTIONS (FS_PERMS) ──────────────────────────────────────────────
-	case "mount.devshm_options":
-		observed, evidence = factMountOptions("/dev/shm")
-	case "mount.tmp_options":
-		observed, evidence = factMountOptions("/tmp")
-	case "mount.vartmp_options":
-		observed, evidence = factMountOptions("/var/tmp")
+	Register(NewValueCollector("mount.devshm_options", "list: mount options of /dev/shm from /proc/mounts", func(req FactRequest) (Value, string, error) { return factMountOptions(req, "/dev/shm") }))
+	Register(NewValueCollector("mount.tmp_options", "list: mount options of /tmp from /proc/mounts", func(req FactRequest) (Value, string, error) { return factMountOptions(req, "/tmp") }))
+	Register(NewValueCollector("mount.vartmp_options", "list: mount options of /var/tmp from /proc/mounts", func(req FactRequest) (Value, string, error) { return factMountOptions(req, "/var/tmp") }))
 
 	// ── FIREWALL / SERVICES ───────────────────────────────────────────────────
-	case "pkg.firewalld_installed":
-		observed, evidence = factPkgFirewalldInstalled()
-	case "svc.firewalld_state":
-		observed, evidence = factSvcFirewalldState(timeout)
-	case "svc.sshd_state":
-		observed, evidence = factSvcSSHState(timeout)
+	Register(NewCollector("pkg.firewalld_installed", "present/absent: firewalld service unit exists", fromEvidence(factPkgFirewalldInstalled)))
+	Register(NewCollector("svc.firewalld_state", "<is-enabled>_<is-active> of firewalld, e.g. enabled_active", factSvcFirewalldState))
+	Register(NewCollector("svc.sshd_state", "systemctl is-active sshd", factSvcSSHState))
 
 	// ── CRYPTO POLICY ─────────────────────────────────────────────────────────
-	case "crypto.policy":
-		observed, evidence = factCryptoPolicy()
+	Register(NewCollector("crypto.policy", "LEGACY/NOT_LEGACY from /etc/crypto-policies/config", factCryptoPolicy))
 
 	// ── SUDO ──────────────────────────────────────────────────────────────────
-	case "sudo.use_pty":
-		observed, evidence = factSudoUsePTY()
-	case "sudo.logfile":
-		observed, evidence = factSudoLogfile()
+	Register(NewValueCollector("sudo.use_pty", "bool: global Defaults enable use_pty", asBool(factSudoUsePTY)))
+	Register(NewValueCollector("sudo.logfile", "bool: global Defaults set a logfile", asBool(factSudoLogfile)))
 
 	// ── ACCOUNTS / PRIVILEGES ─────────────────────────────────────────────────
-	case "acct.uid0_unique":
-		observed, evidence = factAcctUID0Unique()
+	Register(NewValueCollector("acct.uid0_unique", "bool: root is the only UID 0 account", asBool(factAcctUID0Unique)))
+	Register(NewValueCollector("acct.uid0_accounts", "list: accounts with UID 0 in /etc/passwd", factAcctUID0Accounts))
 
 	// ── RECON / PRIVESC ───────────────────────────────────────────────────────
-	case "recon.suid_sgid_unexpected":
-		observed, evidence = factReconSuidSgidUnexpected()
-	case "recon.path_world_writable":
-		observed, evidence = factReconWorldWritablePath()
-
-	default:
-		// Unknown fact: leave observed empty but record a hint in evidence when verbose.
-		evidence = fmt.Sprintf("no collector implemented for fact %q", rule.Fact)
-	}
-
-	facts[rule.Fact] = observed
-	return facts, evidence
+	Register(NewValueCollector("recon.suid_sgid_unexpected", "list: SUID/SGID files under /opt/redcheck-ww", factReconSuidSgidUnexpected))
+	Register(NewValueCollector("recon.path_world_writable", "list: world-writable directories in $PATH", factReconWorldWritablePath))
 }
 
 //
 // ───────────────────────────────── SSH HELPERS ──────────────────────────────
 //
 
-func readLines(path string) ([]string, error) {
-	f, err := os.Open(path)
+// sshdLower resolves an sshd keyword through the config model, lower-cased.
+func sshdLower(req FactRequest, keyword string) (string, string, error) {
+	cfg, err := sshdConfig(req)
 	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	var lines []string
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	return lines, scanner.Err()
-}
-
-func findDirective(lines []string, key string) (string, bool) {
-	keyLower := strings.ToLower(key)
-	var val string
-	found := false
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			continue
-		}
-		if strings.ToLower(parts[0]) == keyLower {
-			val = parts[1]
-			found = true
-			// last one wins, mirroring sshd behaviour
-		}
+		return "", "", err
 	}
-	return val, found
+	val, ev := cfg.Value(keyword)
+	return strings.ToLower(val), ev, nil
 }
 
-func factSSHPermitRootLogin() (string, string) {
-	lines, err := readLines("/etc/ssh/sshd_config")
-	if err != nil {
-		return "", fmt.Sprintf("read /etc/ssh/sshd_config: %v", err)
-	}
-	val, ok := findDirective(lines, "PermitRootLogin")
-	if !ok {
-		return "", "PermitRootLogin not set explicitly; relying on sshd default"
-	}
-	valLower := strings.ToLower(val)
-	return valLower, fmt.Sprintf("PermitRootLogin %s", valLower)
+func factSSHPermitRootLogin(req FactRequest) (string, string, error) {
+	return sshdLower(req, "PermitRootLogin")
 }
 
-func factSSHX11Forwarding() (string, string) {
-	lines, err := readLines("/etc/ssh/sshd_config")
-	if err != nil {
-		return "", fmt.Sprintf("read /etc/ssh/sshd_config: %v", err)
-	}
-	val, ok := findDirective(lines, "X11Forwarding")
-	if !ok {
-		return "", "X11Forwarding not set explicitly; relying on sshd default"
-	}
-	valLower := strings.ToLower(val)
-	return valLower, fmt.Sprintf("X11Forwarding %s", valLower)
+func factSSHX11Forwarding(req FactRequest) (string, string, error) {
+	return sshdLower(req, "X11Forwarding")
 }
 
-func factSSHBanner() (string, string) {
-	lines, err := readLines("/etc/ssh/sshd_config")
+func factSSHBanner(req FactRequest) (string, string, error) {
+	cfg, err := sshdConfig(req)
 	if err != nil {
-		return "", fmt.Sprintf("read /etc/ssh/sshd_config: %v", err)
+		return "", "", err
 	}
-	val, ok := findDirective(lines, "Banner")
-	if !ok {
-		return "absent", "no Banner directive in sshd_config"
+	val, ev := cfg.Value("Banner")
+	if val == "" {
+		return "absent", "no Banner directive in the sshd configuration", nil
 	}
 	if strings.EqualFold(val, "none") {
-		return "absent", "Banner is explicitly set to none"
+		return "absent", "Banner is none: " + ev, nil
 	}
 	if _, err := os.Stat(val); err == nil {
-		return "present", fmt.Sprintf("Banner %s exists", val)
+		return "present", fmt.Sprintf("Banner file %s exists: %s", val, ev), nil
 	}
-	return "absent", fmt.Sprintf("Banner %s configured but file missing", val)
+	return "absent", fmt.Sprintf("Banner file %s missing: %s", val, ev), nil
 }
 
 //
 // ─────────────────────────────── MOUNT OPTIONS ──────────────────────────────
 //
 
-func factMountOptions(target string) (string, string) {
-	data, err := os.ReadFile("/proc/mounts")
+// factMountOptions lists the mount options of target; an empty list when
+// it is not a mount point.
+func factMountOptions(req FactRequest, target string) (Value, string, error) {
+	data, err := req.ReadFile("/proc/mounts")
 	if err != nil {
-		return "", fmt.Sprintf("read /proc/mounts: %v", err)
+		return Value{}, "", fmt.Errorf("read /proc/mounts: %w", err)
 	}
 	lines := strings.Split(string(data), "\n")
 	for _, line := range lines {
@@ -185,17 +156,17 @@ func factMountOptions(target string) (string, string) {
 		mountPoint := fields[1]
 		opts := fields[3]
 		if mountPoint == target {
-			return opts, fmt.Sprintf("from /proc/mounts: %s", line)
+			return StringList(strings.Split(opts, ",")), fmt.Sprintf("from /proc/mounts: %s", line), nil
 		}
 	}
-	return "", fmt.Sprintf("mount point %s not found in /proc/mounts", target)
+	return StringList(nil), fmt.Sprintf("mount point %s not found in /proc/mounts", target), nil
 }
 
 //
 // ─────────────────────────────── SERVICES / FIREWALL ───────────────────────
 //
 
-func factPkgFirewalldInstalled() (string, string) {
+func factPkgFirewalldInstalled(FactRequest) (string, string) {
 	paths := []string{
 		"/usr/lib/systemd/system/firewalld.service",
 		"/lib/systemd/system/firewalld.service",
@@ -208,163 +179,124 @@ func factPkgFirewalldInstalled() (string, string) {
 	return "absent", "firewalld service unit not found"
 }
 
-func runCommand(timeout time.Duration, name string, args ...string) (string, string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, name, args...)
-	var outBuf, errBuf bytes.Buffer
-	cmd.Stdout = &outBuf
-	cmd.Stderr = &errBuf
-
-	err := cmd.Run()
-	out := strings.TrimSpace(outBuf.String())
-	errOut := strings.TrimSpace(errBuf.String())
-	return out, errOut, err
+// runCommand runs name through Runner; the process is killed when ctx is done.
+func runCommand(ctx context.Context, name string, args ...string) (string, string, error) {
+	out, errOut, _, err := Runner.Run(ctx, name, args)
+	return strings.TrimSpace(out), strings.TrimSpace(errOut), err
 }
 
-func factSvcFirewalldState(timeout time.Duration) (string, string) {
-	enabled, errOut1, err1 := runCommand(timeout, "systemctl", "is-enabled", "firewalld")
-	active, errOut2, err2 := runCommand(timeout, "systemctl", "is-active", "firewalld")
+func factSvcFirewalldState(req FactRequest) (string, string, error) {
+	enabled, errOut1, err1 := req.Run("systemctl", "is-enabled", "firewalld")
+	active, errOut2, err2 := req.Run("systemctl", "is-active", "firewalld")
 
-	if err1 != nil && err2 != nil {
-		return "", fmt.Sprintf("systemctl is-enabled/is-active firewalld failed: %v / %v (stderr: %s / %s)", err1, err2, errOut1, errOut2)
+	// both exit non-zero for disabled/inactive units; only empty output means failure
+	if enabled == "" && active == "" && err1 != nil && err2 != nil {
+		return "", "", fmt.Errorf("systemctl is-enabled/is-active firewalld failed: %v / %v (stderr: %s / %s)", err1, err2, errOut1, errOut2)
 	}
 
 	enabled = strings.TrimSpace(enabled)
 	active = strings.TrimSpace(active)
 
 	if enabled == "enabled" && active == "active" {
-		return "enabled_active", fmt.Sprintf("firewalld is-enabled=%s, is-active=%s", enabled, active)
+		return "enabled_active", fmt.Sprintf("firewalld is-enabled=%s, is-active=%s", enabled, active), nil
 	}
 
 	state := fmt.Sprintf("%s_%s", enabled, active)
-	return state, fmt.Sprintf("firewalld is-enabled=%s, is-active=%s", enabled, active)
+	return state, fmt.Sprintf("firewalld is-enabled=%s, is-active=%s", enabled, active), nil
 }
 
-func factSvcSSHState(timeout time.Duration) (string, string) {
-	active, errOut, err := runCommand(timeout, "systemctl", "is-active", "sshd")
-	if err != nil {
-		return "", fmt.Sprintf("systemctl is-active sshd failed: %v (stderr: %s)", err, errOut)
+func factSvcSSHState(req FactRequest) (string, string, error) {
+	active, errOut, err := req.Run("systemctl", "is-active", "sshd")
+	if active == "" && err != nil {
+		return "", "", fmt.Errorf("systemctl is-active sshd failed: %v (stderr: %s)", err, errOut)
 	}
 	active = strings.TrimSpace(active)
-	return active, fmt.Sprintf("sshd is-active=%s", active)
+	return active, fmt.Sprintf("sshd is-active=%s", active), nil
 }
 
 //
 // ─────────────────────────────── CRYPTO POLICY ──────────────────────────────
 //
 
-func factCryptoPolicy() (string, string) {
-	data, err := os.ReadFile("/etc/crypto-policies/config")
+func factCryptoPolicy(req FactRequest) (string, string, error) {
+	data, err := req.ReadFile("/etc/crypto-policies/config")
 	if err != nil {
-		return "", fmt.Sprintf("read /etc/crypto-policies/config: %v", err)
+		return "", "", fmt.Errorf("read /etc/crypto-policies/config: %w", err)
 	}
 	policy := strings.TrimSpace(string(data))
 	if strings.EqualFold(policy, "LEGACY") {
-		return "LEGACY", fmt.Sprintf("crypto policy = %s", policy)
+		return "LEGACY", fmt.Sprintf("crypto policy = %s", policy), nil
 	}
-	return "NOT_LEGACY", fmt.Sprintf("crypto policy = %s", policy)
+	return "NOT_LEGACY", fmt.Sprintf("crypto policy = %s", policy), nil
 }
 
 //
 // ───────────────────────────────────── SUDO ─────────────────────────────────
 //
 
-func readSudoConfigLines() ([]string, error) {
-	var paths []string
-	paths = append(paths, "/etc/sudoers")
-
-	if entries, err := os.ReadDir("/etc/sudoers.d"); err == nil {
-		for _, e := range entries {
-			if !e.IsDir() {
-				paths = append(paths, filepath.Join("/etc/sudoers.d", e.Name()))
-			}
-		}
+func factSudoUsePTY(req FactRequest) (string, string, error) {
+	cfg, err := sudoersConfig(req)
+	if err != nil {
+		return "", "", err
 	}
+	v, ev := sudoFlag(cfg, "use_pty")
+	return v, ev, nil
+}
 
-	var all []string
-	for _, p := range paths {
-		f, err := os.Open(p)
-		if err != nil {
-			continue
-		}
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			all = append(all, scanner.Text())
-		}
-		_ = f.Close()
+func factSudoLogfile(req FactRequest) (string, string, error) {
+	cfg, err := sudoersConfig(req)
+	if err != nil {
+		return "", "", err
 	}
-	return all, nil
+	v, ev := sudoFlag(cfg, "logfile")
+	return v, ev, nil
 }
 
-func factSudoUsePTY() (string, string) {
-	lines, err := readSudoConfigLines()
+//
+// ───────────────────────────── ACCOUNTS / PRIVILEGES ───────────────────────
+//
+
+func factAcctUID0Unique(req FactRequest) (string, string, error) {
+	uid0Users, err := uid0Accounts(req)
 	if err != nil {
-		return "", fmt.Sprintf("read sudo config: %v", err)
+		return "", "", err
 	}
-	for _, line := range lines {
-		trim := strings.TrimSpace(line)
-		if trim == "" || strings.HasPrefix(trim, "#") {
-			continue
-		}
-		if strings.Contains(trim, "Defaults") && strings.Contains(trim, "use_pty") {
-			return "true", fmt.Sprintf("matched: %s", trim)
-		}
+
+	if len(uid0Users) == 1 && uid0Users[0] == "root" {
+		return "true", "only UID 0 account is root", nil
 	}
-	return "false", "no 'Defaults use_pty' found in sudoers or sudoers.d"
+	if len(uid0Users) == 0 {
+		// Extremely weird, but let's be explicit
+		return "false", "no UID 0 accounts found in /etc/passwd", nil
+	}
+	return "false", fmt.Sprintf("UID 0 accounts: %s", strings.Join(uid0Users, ", ")), nil
 }
 
-func factSudoLogfile() (string, string) {
-	lines, err := readSudoConfigLines()
+func factAcctUID0Accounts(req FactRequest) (Value, string, error) {
+	uid0Users, err := uid0Accounts(req)
 	if err != nil {
-		return "", fmt.Sprintf("read sudo config: %v", err)
-	}
-	for _, line := range lines {
-		trim := strings.TrimSpace(line)
-		if trim == "" || strings.HasPrefix(trim, "#") {
-			continue
-		}
-		if strings.Contains(trim, "Defaults") && strings.Contains(trim, "logfile=") {
-			return "true", fmt.Sprintf("matched: %s", trim)
-		}
+		return Value{}, "", err
 	}
-	return "false", "no 'Defaults logfile=…' found in sudoers or sudoers.d"
+	return StringList(uid0Users), fmt.Sprintf("%d UID 0 account(s) in /etc/passwd", len(uid0Users)), nil
 }
 
-//
-// ───────────────────────────── ACCOUNTS / PRIVILEGES ───────────────────────
-//
-
-func factAcctUID0Unique() (string, string) {
-	data, err := os.ReadFile("/etc/passwd")
+// uid0Accounts returns the names of every UID 0 account in /etc/passwd.
+func uid0Accounts(req FactRequest) ([]string, error) {
+	data, err := req.ReadFile("/etc/passwd")
 	if err != nil {
-		return "", fmt.Sprintf("read /etc/passwd: %v", err)
+		return nil, fmt.Errorf("read /etc/passwd: %w", err)
 	}
-	lines := strings.Split(string(data), "\n")
-
-	var uid0Users []string
-	for _, line := range lines {
+	var users []string
+	for _, line := range strings.Split(string(data), "\n") {
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 		parts := strings.Split(line, ":")
-		if len(parts) < 3 {
-			continue
+		if len(parts) >= 3 && parts[2] == "0" {
+			users = append(users, parts[0])
 		}
-		if parts[2] == "0" {
-			uid0Users = append(uid0Users, parts[0])
-		}
-	}
-
-	if len(uid0Users) == 1 && uid0Users[0] == "root" {
-		return "true", "only UID 0 account is root"
-	}
-	if len(uid0Users) == 0 {
-		// Extremely weird, but let's be explicit
-		return "false", "no UID 0 accounts found in /etc/passwd"
 	}
-	return "false", fmt.Sprintf("UID 0 accounts: %s", strings.Join(uid0Users, ", "))
+	return users, nil
 }
 
 //
@@ -373,17 +305,20 @@ func factAcctUID0Unique() (string, string) {
 
 // For your lab, we keep this intentionally scoped to /opt/redcheck-ww
 // to avoid a super-heavy full-disk find() on every run.
-func factReconSuidSgidUnexpected() (string, string) {
+func factReconSuidSgidUnexpected(req FactRequest) (Value, string, error) {
 	root := "/opt/redcheck-ww"
 
 	info, err := os.Stat(root)
 	if err != nil || !info.IsDir() {
-		return "none", "no /opt/redcheck-ww directory (nothing to check)"
+		return StringList(nil), "no /opt/redcheck-ww directory (nothing to check)", nil
 	}
 
 	var hits []string
 
-	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+	walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			return nil
 		}
@@ -398,18 +333,20 @@ func factReconSuidSgidUnexpected() (string, string) {
 		}
 		return nil
 	})
+	if walkErr != nil {
+		return Value{}, "", fmt.Errorf("walk %s: %w", root, walkErr)
+	}
 
 	if len(hits) == 0 {
-		return "none", "no SUID/SGID files found under /opt/redcheck-ww"
+		return StringList(nil), "no SUID/SGID files found under /opt/redcheck-ww", nil
 	}
-
-	return strings.Join(hits, ","), "unexpected SUID/SGID files: " + strings.Join(hits, ", ")
+	return StringList(hits), fmt.Sprintf("%d unexpected SUID/SGID file(s)", len(hits)), nil
 }
 
-func factReconWorldWritablePath() (string, string) {
+func factReconWorldWritablePath(FactRequest) (Value, string, error) {
 	pathEnv := os.Getenv("PATH")
 	if pathEnv == "" {
-		return "none", "PATH is empty"
+		return StringList(nil), "PATH is empty", nil
 	}
 
 	dirs := filepath.SplitList(pathEnv)
@@ -430,19 +367,33 @@ func factReconWorldWritablePath() (string, string) {
 	}
 
 	if len(ww) == 0 {
-		return "none", "no world-writable directories in PATH"
+		return StringList(nil), "no world-writable directories in PATH", nil
 	}
-	return strings.Join(ww, ":"), "world-writable PATH dirs: " + strings.Join(ww, ", ")
+	return StringList(ww), fmt.Sprintf("%d world-writable PATH dir(s)", len(ww)), nil
 }
 
 //
 // ────────────────────────────── SMALL STRING HELPERS ───────────────────────
 //
 
-func evaluateAllOf(observed string, expectedAll []string) []string {
+// evaluateAllOf returns the wanted tokens missing from observed: elements
+// of a list or map fact, substrings of a string fact.
+func evaluateAllOf(observed Value, expectedAll []string) []string {
+	have := func(want string) bool { return strings.Contains(observed.String(), want) }
+	if observed.IsCollection() {
+		items := observed.Items()
+		have = func(want string) bool {
+			for _, it := range items {
+				if it == want {
+					return true
+				}
+			}
+			return false
+		}
+	}
 	var missing []string
 	for _, want := range expectedAll {
-		if !strings.Contains(observed, want) {
+		if !have(want) {
 			missing = append(missing, want)
 		}
 	}
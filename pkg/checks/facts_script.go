@@ -0,0 +1,189 @@
+package checks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/execx"
+)
+
+// ScriptFact is a fact a rule pack defines with a command and an output
+// parser, for site tooling such as an EDR health wrapper:
+//
+//	facts:
+//	  - name: site.edr_health
+//	    command: /opt/edr/bin/edrctl
+//	    args: ["status", "--json"]
+//	    parser: json
+//	    path: agent.health
+//
+// The command must be on the scan's allow-list (--allow-exec) and runs with
+// a fixed minimal environment, killed when the rule times out.
+type ScriptFact struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Command     string   `yaml:"command"` // absolute path
+	Args        []string `yaml:"args"`
+	// Parser turns the output into the fact value:
+	//   trim      (default) stdout without surrounding whitespace
+	//   regex     first capture group of Pattern in stdout (or the whole match)
+	//   exit_code the exit status as an int; a non-zero exit is not an error
+	//   json      the value at dotted Path in stdout parsed as JSON ("a.b.0.c"),
+	//             typed: arrays become lists, objects maps
+	Parser  string `yaml:"parser"`
+	Pattern string `yaml:"pattern"`
+	Path    string `yaml:"path"`
+
+	Source string `yaml:"-"` // "file:line", set by the loader
+}
+
+// Script fact parsers.
+const (
+	ParserTrim     = "trim"
+	ParserRegex    = "regex"
+	ParserExitCode = "exit_code"
+	ParserJSON     = "json"
+)
+
+// scriptEnv is the whole environment of a script fact command.
+var scriptEnv = []string{
+	"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+	"LANG=C",
+	"LC_ALL=C",
+	"HOME=/",
+}
+
+var scriptFactNameRE = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_]+)+$`)
+
+// Validate checks the definition; it does not consult the allow-list.
+func (f ScriptFact) Validate() []error {
+	var errs []error
+	bad := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+
+	if !scriptFactNameRE.MatchString(f.Name) {
+		bad("fact name %q must be dotted lower-case words, e.g. site.edr_health", f.Name)
+	}
+	if !filepath.IsAbs(f.Command) || filepath.Clean(f.Command) != f.Command {
+		bad("fact %s: command %q must be a clean absolute path", f.Name, f.Command)
+	}
+	switch f.Parser {
+	case "", ParserTrim, ParserExitCode:
+	case ParserRegex:
+		if f.Pattern == "" {
+			bad("fact %s: regex parser needs a pattern", f.Name)
+		} else if _, err := regexp.Compile(f.Pattern); err != nil {
+			bad("fact %s: bad pattern: %v", f.Name, err)
+		}
+	case ParserJSON:
+		if f.Path == "" {
+			bad("fact %s: json parser needs a path", f.Name)
+		}
+	default:
+		bad("fact %s: unknown parser %q (want trim, regex, exit_code or json)", f.Name, f.Parser)
+	}
+	return errs
+}
+
+// Collector returns the FactCollector for f. f must be valid.
+func (f ScriptFact) Collector() FactCollector {
+	desc := f.Description
+	if desc == "" {
+		desc = "script: " + f.Command
+	}
+	var re *regexp.Regexp
+	if f.Parser == ParserRegex {
+		re = regexp.MustCompile(f.Pattern)
+	}
+	return NewValueCollector(f.Name, desc, func(req FactRequest) (Value, string, error) {
+		return f.collect(req, re)
+	})
+}
+
+// CommandAllowed reports whether path is on the --allow-exec list.
+func CommandAllowed(path string) bool {
+	for _, a := range AllowedCommands {
+		if a == path {
+			return true
+		}
+	}
+	return false
+}
+
+func (f ScriptFact) collect(req FactRequest, re *regexp.Regexp) (Value, string, error) {
+	if !CommandAllowed(f.Command) {
+		return Value{}, "", fmt.Errorf("command %s is not allowed; pass --allow-exec %s to run it", f.Command, f.Command)
+	}
+	runner, ok := Runner.(execx.EnvRunner)
+	if !ok {
+		return Value{}, "", fmt.Errorf("the command runner cannot run %s with a sanitised environment", f.Command)
+	}
+
+	stdout, stderr, code, err := runner.RunEnv(req.Context(), scriptEnv, f.Command, f.Args)
+	evidence := fmt.Sprintf("ran %s (exit %d)", strings.Join(append([]string{f.Command}, f.Args...), " "), code)
+	var exitErr *exec.ExitError
+	switch {
+	case err != nil && !errors.As(err, &exitErr):
+		return Value{}, evidence, fmt.Errorf("run %s: %w", f.Command, err) // did not start, or timed out
+	case f.Parser == ParserExitCode:
+		return IntValue(int64(code)), evidence, nil
+	case err != nil:
+		if msg := strings.TrimSpace(stderr); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return Value{}, evidence, fmt.Errorf("%s: %w", f.Command, err)
+	}
+
+	switch f.Parser {
+	case ParserRegex:
+		m := re.FindStringSubmatch(stdout)
+		if m == nil {
+			return Value{}, evidence + "; pattern did not match", nil
+		}
+		if len(m) > 1 {
+			return StringValue(m[1]), evidence, nil
+		}
+		return StringValue(m[0]), evidence, nil
+	case ParserJSON:
+		v, err := jsonPath(stdout, f.Path)
+		if err != nil {
+			return Value{}, evidence, fmt.Errorf("%s: %w", f.Command, err)
+		}
+		return ValueOf(v), evidence, nil
+	default:
+		return StringValue(strings.TrimSpace(stdout)), evidence, nil
+	}
+}
+
+// jsonPath returns the value at a dotted path ("agent.checks.0.state") in
+// doc.
+func jsonPath(doc, path string) (any, error) {
+	var v any
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		return nil, fmt.Errorf("output is not JSON: %w", err)
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("json path %q: no key %q", path, key)
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("json path %q: bad index %q", path, key)
+			}
+			v = node[i]
+		default:
+			return nil, fmt.Errorf("json path %q: %q is not an object or array", path, key)
+		}
+	}
+	return v, nil
+}
@@ -3,11 +3,76 @@ package checks
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 )
 
+func init() {
+	Register(NewCollector("pkg.installed:", "present/absent: package <name> installed (rpm, falling back to dpkg)", factPkgInstalled))
+	Register(NewCollector("svc.enabled:", "systemctl is-enabled <unit> (enabled, disabled, masked, ...)", factSvcEnabled))
+	Register(NewCollector("svc.active:", "systemctl is-active <unit> (active, inactive, failed, ...)", factSvcActive))
+}
+
+// factPkgInstalled asks rpm, then dpkg-query. Only their "not installed"
+// answer (exit 1 with that message) means absent; a timeout, a locked
+// package database or any other failure is a collection error.
+func factPkgInstalled(req FactRequest) (string, string, error) {
+	name := req.Param
+	out, errOut, err := req.Run("rpm", "-q", name)
+	switch {
+	case err == nil:
+		return "present", fmt.Sprintf("rpm -q %s: %s", name, out), nil
+	case exitStatus(err) == 1 && strings.Contains(out, "is not installed"):
+		return "absent", fmt.Sprintf("rpm -q %s: %s", name, out), nil
+	case !errors.Is(err, exec.ErrNotFound):
+		return "", "", fmt.Errorf("rpm -q %s: %w (stderr: %s)", name, err, errOut)
+	}
+
+	// no rpm on this host: try the Debian package database
+	out, errOut, err = req.Run("dpkg-query", "-W", "-f=${Status}", name)
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		return "", "", errors.New("neither rpm nor dpkg-query available")
+	case err == nil && strings.Contains(out, "install ok installed"):
+		return "present", fmt.Sprintf("dpkg-query %s: %s", name, out), nil
+	case err == nil, exitStatus(err) == 1 && strings.Contains(errOut, "no packages found"):
+		return "absent", fmt.Sprintf("dpkg-query %s: not installed", name), nil
+	}
+	return "", "", fmt.Errorf("dpkg-query -W %s: %w (stderr: %s)", name, err, errOut)
+}
+
+// exitStatus is the exit code carried by a command error, or -1 when the
+// command did not run to completion (not found, killed, timed out).
+func exitStatus(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// systemctl is-enabled/is-active exit non-zero for disabled/inactive units
+// but still print the state, so stdout is what matters. No state at all
+// (no systemd, D-Bus down, timeout) is a collection error.
+func factSvcEnabled(req FactRequest) (string, string, error) {
+	out, errOut, err := req.Run("systemctl", "is-enabled", req.Param)
+	if out == "" && err != nil {
+		return "", "", fmt.Errorf("systemctl is-enabled %s: %w (stderr: %s)", req.Param, err, errOut)
+	}
+	return out, fmt.Sprintf("%s is-enabled=%s", req.Param, out), nil
+}
+
+func factSvcActive(req FactRequest) (string, string, error) {
+	out, errOut, err := req.Run("systemctl", "is-active", req.Param)
+	if out == "" && err != nil {
+		return "", "", fmt.Errorf("systemctl is-active %s: %w (stderr: %s)", req.Param, err, errOut)
+	}
+	return out, fmt.Sprintf("%s is-active=%s", req.Param, out), nil
+}
+
 // internal helpers
 func runOut(name string, args ...string) (string, error) {
 	var buf bytes.Buffer
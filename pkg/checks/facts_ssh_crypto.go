@@ -0,0 +1,171 @@
+package checks
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// sshCryptoBackend is the sshd back-end of the system crypto policy. RHEL 9
+// pulls it in with an Include from sshd_config.d/50-redhat.conf; RHEL 8
+// passes it to sshd on the command line as $CRYPTO_POLICY.
+const sshCryptoBackend = "/etc/crypto-policies/back-ends/opensshserver.config"
+
+// sshAlgorithmFacts are the list facts of sshd's negotiated algorithms.
+// Each also has an "ssh.weak_*" fact listing the WeakSSHAlgorithms it
+// enables.
+var sshAlgorithmFacts = []struct {
+	fact    string
+	keyword string
+	aliases []string // older spellings sshd still accepts
+}{
+	{"ssh.ciphers", "Ciphers", nil},
+	{"ssh.macs", "MACs", nil},
+	{"ssh.kex_algorithms", "KexAlgorithms", nil},
+	{"ssh.host_key_algorithms", "HostKeyAlgorithms", nil},
+	{"ssh.pubkey_accepted_algorithms", "PubkeyAcceptedAlgorithms", []string{"PubkeyAcceptedKeyTypes"}},
+}
+
+// WeakSSHAlgorithms is the curated list of algorithms sshd should not
+// offer, as path.Match patterns per keyword: CBC and RC4 ciphers, MD5,
+// RIPEMD-160, SHA-1 and 64-bit-tag MACs, SHA-1 and 1024-bit DH key
+// exchange, DSA keys and SHA-1 RSA signatures.
+var WeakSSHAlgorithms = map[string][]string{
+	"Ciphers": {"*-cbc", "*-cbc@*", "arcfour*", "none"},
+	"MACs": {"hmac-md5*", "hmac-ripemd160*", "hmac-sha1", "hmac-sha1-*",
+		"umac-64@openssh.com", "umac-64-etm@openssh.com"},
+	"KexAlgorithms": {"diffie-hellman-group1-sha1", "diffie-hellman-group14-sha1",
+		"diffie-hellman-group-exchange-sha1", "gss-*sha1-*"},
+	"HostKeyAlgorithms":        {"ssh-dss*", "ssh-rsa", "ssh-rsa-cert-v01@openssh.com"},
+	"PubkeyAcceptedAlgorithms": {"ssh-dss*", "ssh-rsa", "ssh-rsa-cert-v01@openssh.com"},
+}
+
+func init() {
+	for _, a := range sshAlgorithmFacts {
+		a := a
+		names := append([]string{a.keyword}, a.aliases...)
+		Register(NewValueCollector(a.fact, "list: effective sshd "+a.keyword+" (sshd -T, config or crypto-policy back-end)",
+			func(req FactRequest) (Value, string, error) {
+				algs, ev, err := sshAlgorithms(req, names)
+				return StringList(algs), ev, err
+			}))
+		Register(NewValueCollector("ssh.weak_"+strings.TrimPrefix(a.fact, "ssh."), "list: weak algorithms in sshd "+a.keyword,
+			func(req FactRequest) (Value, string, error) {
+				algs, ev, err := sshAlgorithms(req, names)
+				if err != nil {
+					return Value{}, ev, err
+				}
+				weak := WeakSSHAlgorithmsIn(a.keyword, algs)
+				return StringList(weak), fmt.Sprintf("%d of %d offered algorithm(s) weak; %s", len(weak), len(algs), ev), nil
+			}))
+	}
+}
+
+// WeakSSHAlgorithmsIn returns the algorithms in algs that match
+// WeakSSHAlgorithms[keyword].
+func WeakSSHAlgorithmsIn(keyword string, algs []string) []string {
+	var weak []string
+	for _, alg := range algs {
+		for _, pattern := range WeakSSHAlgorithms[keyword] {
+			if ok, _ := path.Match(pattern, strings.ToLower(alg)); ok {
+				weak = append(weak, alg)
+				break
+			}
+		}
+	}
+	return weak
+}
+
+// sshAlgorithms resolves the algorithm list sshd uses for a keyword (and
+// its aliases), in order of authority: sshd -T; a RHEL 8 style back-end
+// passed on the command line, which overrides the files; the config model,
+// which includes the RHEL 9 back-end. A list edited with +, - or ^ is
+// relative to sshd's compiled-in defaults and needs sshd -T.
+func sshAlgorithms(req FactRequest, names []string) ([]string, string, error) {
+	cfg, err := sshdConfig(req)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, k := range names {
+		if v, ok := cfg.Effective[strings.ToLower(k)]; ok {
+			return splitAlgorithms(v), "from sshd -T", nil
+		}
+	}
+
+	policy := sshCryptoPolicyName(req)
+	if backend, ok := sshCmdlineBackend(req); ok {
+		for _, k := range names {
+			if v, ok := backend[strings.ToLower(k)]; ok {
+				return splitAlgorithms(v), fmt.Sprintf("from %s passed to sshd as CRYPTO_POLICY%s", sshCryptoBackend, policy), nil
+			}
+		}
+	}
+
+	for _, k := range names {
+		s, ok := cfg.Lookup(k)
+		if !ok {
+			continue
+		}
+		if s.Value != "" && strings.ContainsRune("+-^", rune(s.Value[0])) {
+			return nil, "", fmt.Errorf("%s %s (%s) edits sshd's built-in list, which only sshd -T can resolve (run as root)", s.Keyword, s.Value, s.Pos())
+		}
+		ev := fmt.Sprintf("from %s %s (%s)", s.Keyword, s.Value, s.Pos())
+		if s.File == sshCryptoBackend {
+			ev = fmt.Sprintf("from the crypto-policy back-end (%s)%s", s.Pos(), policy)
+		}
+		return splitAlgorithms(s.Value), ev, nil
+	}
+	return nil, "", fmt.Errorf("effective %s unknown: not set in the sshd configuration, no crypto-policy back-end, and sshd -T unavailable (it needs root and the sshd binary)", names[0])
+}
+
+// sshCmdlineBackend parses a RHEL 8 back-end,
+//
+//	CRYPTO_POLICY='-oCiphers=aes256-gcm@openssh.com,... -oMACs=...'
+//
+// unless /etc/sysconfig/sshd opts out by setting CRYPTO_POLICY itself.
+func sshCmdlineBackend(req FactRequest) (map[string]string, bool) {
+	data, err := req.ReadFile(sshCryptoBackend)
+	if err != nil {
+		return nil, false
+	}
+	_, opts, ok := strings.Cut(string(data), "CRYPTO_POLICY=")
+	if !ok {
+		return nil, false // RHEL 9 format, read through Include
+	}
+	if lines, err := req.ReadLines("/etc/sysconfig/sshd"); err == nil {
+		for _, l := range lines {
+			if strings.HasPrefix(strings.TrimSpace(l), "CRYPTO_POLICY=") {
+				return nil, false
+			}
+		}
+	}
+	out := map[string]string{}
+	for _, f := range strings.Fields(strings.Trim(strings.TrimSpace(opts), `'"`)) {
+		if k, v, ok := strings.Cut(strings.TrimPrefix(f, "-o"), "="); ok {
+			out[strings.ToLower(k)] = v
+		}
+	}
+	return out, true
+}
+
+// sshCryptoPolicyName returns " (policy NAME)" for evidence, or "".
+func sshCryptoPolicyName(req FactRequest) string {
+	data, err := req.ReadFile("/etc/crypto-policies/config")
+	if err != nil {
+		return ""
+	}
+	if name := strings.TrimSpace(string(data)); name != "" {
+		return " (policy " + name + ")"
+	}
+	return ""
+}
+
+func splitAlgorithms(s string) []string {
+	var out []string
+	for _, a := range strings.Split(s, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
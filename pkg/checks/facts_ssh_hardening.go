@@ -0,0 +1,142 @@
+package checks
+
+import (
+	"fmt"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SSH hardening facts for the CIS 5.1.x rules. Each resolves its keyword
+// through the sshd config model, so Includes, first-value-wins, sshd -T and
+// compiled-in defaults are handled the same way as for sshd.directive:.
+func init() {
+	Register(NewCollector("ssh.log_level", "effective LogLevel of sshd (lower-cased)", sshdLowerFact("LogLevel")))
+	Register(NewCollector("ssh.permit_user_environment", "effective PermitUserEnvironment of sshd (lower-cased)", sshdLowerFact("PermitUserEnvironment")))
+	Register(NewCollector("ssh.allow_tcp_forwarding", "effective AllowTcpForwarding of sshd (lower-cased)", sshdLowerFact("AllowTcpForwarding")))
+
+	Register(NewValueCollector("ssh.ignore_rhosts", "bool: effective IgnoreRhosts of sshd", sshdBoolFact("IgnoreRhosts")))
+	Register(NewValueCollector("ssh.hostbased_authentication", "bool: effective HostbasedAuthentication of sshd", sshdBoolFact("HostbasedAuthentication")))
+	Register(NewValueCollector("ssh.permit_empty_passwords", "bool: effective PermitEmptyPasswords of sshd", sshdBoolFact("PermitEmptyPasswords")))
+	Register(NewValueCollector("ssh.use_pam", "bool: effective UsePAM of sshd", sshdBoolFact("UsePAM")))
+	Register(NewValueCollector("ssh.disable_forwarding", "bool: effective DisableForwarding of sshd", sshdBoolFact("DisableForwarding")))
+
+	Register(NewValueCollector("ssh.max_auth_tries", "effective MaxAuthTries of sshd", sshdIntFact("MaxAuthTries", strconv.Atoi)))
+	Register(NewValueCollector("ssh.max_sessions", "effective MaxSessions of sshd", sshdIntFact("MaxSessions", strconv.Atoi)))
+	Register(NewValueCollector("ssh.client_alive_count_max", "effective ClientAliveCountMax of sshd", sshdIntFact("ClientAliveCountMax", strconv.Atoi)))
+	Register(NewValueCollector("ssh.client_alive_interval", "effective ClientAliveInterval of sshd in seconds", sshdIntFact("ClientAliveInterval", sshdParseSeconds)))
+	Register(NewValueCollector("ssh.login_grace_time", "effective LoginGraceTime of sshd in seconds", sshdIntFact("LoginGraceTime", sshdParseSeconds)))
+
+	for i, field := range []string{"start", "rate", "full"} {
+		i := i
+		Register(NewValueCollector("ssh.max_startups_"+field, "effective "+field+" field of sshd MaxStartups (start:rate:full)",
+			func(req FactRequest) (Value, string, error) { return factSSHMaxStartups(req, i) }))
+	}
+
+	Register(NewValueCollector("ssh.host_keys_loose", "list: SSH host private keys not 0600 root:root or 0640 root:ssh_keys", factSSHHostKeysLoose))
+}
+
+func sshdLowerFact(keyword string) func(FactRequest) (string, string, error) {
+	return func(req FactRequest) (string, string, error) { return sshdLower(req, keyword) }
+}
+
+// sshdBoolFact maps yes/no to a bool; anything else stays a string.
+func sshdBoolFact(keyword string) func(FactRequest) (Value, string, error) {
+	return func(req FactRequest) (Value, string, error) {
+		val, ev, err := sshdLower(req, keyword)
+		switch {
+		case err != nil:
+			return Value{}, ev, err
+		case val == "yes" || val == "no":
+			return BoolValue(val == "yes"), ev, nil
+		}
+		return StringValue(val), ev, nil
+	}
+}
+
+// sshdIntFact parses the keyword's value with parse; an unparsable value
+// stays a string, which fails numeric operators.
+func sshdIntFact(keyword string, parse func(string) (int, error)) func(FactRequest) (Value, string, error) {
+	return func(req FactRequest) (Value, string, error) {
+		val, ev, err := sshdLower(req, keyword)
+		if err != nil {
+			return Value{}, ev, err
+		}
+		n, err := parse(val)
+		if err != nil {
+			return StringValue(val), ev, nil
+		}
+		return IntValue(int64(n)), ev, nil
+	}
+}
+
+func sshdParseSeconds(s string) (int, error) {
+	n, ok := sshdSeconds(s)
+	if !ok {
+		return 0, fmt.Errorf("bad sshd time %q", s)
+	}
+	return n, nil
+}
+
+// factSSHMaxStartups returns one field of MaxStartups. A bare "start" means
+// sshd refuses every connection past start, i.e. start:100:start.
+func factSSHMaxStartups(req FactRequest, field int) (Value, string, error) {
+	val, ev, err := sshdLower(req, "MaxStartups")
+	if err != nil {
+		return Value{}, ev, err
+	}
+	parts := strings.Split(val, ":")
+	if len(parts) == 1 {
+		parts = []string{parts[0], "100", parts[0]}
+	}
+	if len(parts) != 3 {
+		return StringValue(val), ev, nil
+	}
+	n, err := strconv.Atoi(parts[field])
+	if err != nil {
+		return StringValue(val), ev, nil
+	}
+	return IntValue(int64(n)), ev, nil
+}
+
+// factSSHHostKeysLoose lists the host private keys sshd loads (HostKey, or
+// the /etc/ssh/ssh_host_*_key defaults) that others can read or that are not
+// owned by root. Group read is allowed for the ssh_keys group RHEL uses.
+func factSSHHostKeysLoose(req FactRequest) (Value, string, error) {
+	cfg, err := sshdConfig(req)
+	if err != nil {
+		return Value{}, "", err
+	}
+	var keys []string
+	if v, ok := cfg.Effective["hostkey"]; ok {
+		keys = strings.Fields(v)
+	} else if s, ok := cfg.Lookup("HostKey"); ok {
+		keys = strings.Fields(s.Value)
+	} else {
+		keys, _ = filepath.Glob("/etc/ssh/ssh_host_*_key")
+	}
+
+	var loose []string
+	checked := 0
+	for _, k := range keys {
+		st, err := statUnix(k)
+		if err != nil {
+			continue // sshd skips missing keys too
+		}
+		checked++
+		mode := st.Mode & 0o7777
+		group := strconv.FormatUint(uint64(st.Gid), 10)
+		if g, err := user.LookupGroupId(group); err == nil {
+			group = g.Name
+		}
+		allowed := uint32(0o600)
+		if group == "ssh_keys" {
+			allowed = 0o640
+		}
+		if st.Uid != 0 || mode&^allowed != 0 || (st.Gid != 0 && group != "ssh_keys") {
+			loose = append(loose, fmt.Sprintf("%s %04o uid=%d group=%s", k, mode, st.Uid, group))
+		}
+	}
+	return StringList(loose), fmt.Sprintf("%d host key(s) checked, %d too permissive", checked, len(loose)), nil
+}
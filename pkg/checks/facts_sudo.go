@@ -1,102 +1,169 @@
 package checks
 
 import (
-	"os"
-	"path/filepath"
-	"regexp"
+	"fmt"
 	"strconv"
 	"strings"
 )
 
-func sudoersContent() string {
-	paths := []string{"/etc/sudoers"}
-	dir := "/etc/sudoers.d"
-	if ents, err := os.ReadDir(dir); err == nil {
-		for _, e := range ents {
-			if !e.IsDir() {
-				paths = append(paths, filepath.Join(dir, e.Name()))
-			}
-		}
-	}
-	var b strings.Builder
-	for _, p := range paths {
-		if data, err := os.ReadFile(p); err == nil {
-			b.Write(data)
-			b.WriteByte('\n')
-		}
-	}
-	return b.String()
+func init() {
+	Register(NewValueCollector("sudo.timestamp_timeout_sane", "bool: sudo timestamp_timeout unset or 0-15 minutes", asBool(factSudoTimestampTimeoutSane)))
+	Register(NewValueCollector("sudo.nopasswd_wildcard_forbidden", "bool: no grant runs ALL with NOPASSWD", asBool(factSudoNoPasswdWildcardForbidden)))
+	Register(NewValueCollector("sudo.any_command_users", "list: non-root users who may run any command as root", factSudoAnyCommandUsers(false)))
+	Register(NewValueCollector("sudo.any_command_nopasswd_users", "list: non-root users who may run any command as root without a password", factSudoAnyCommandUsers(true)))
+	Register(NewValueCollector("sudo.nopasswd_grants", "list: grants a local account runs without a password (NOPASSWD or Defaults !authenticate)", factSudoNoPasswdGrants))
+	Register(NewValueCollector("sudo.root_commands:", "list: commands <user> may run as root via sudo", factSudoRootCommands))
 }
 
-// "Defaults timestamp_timeout=15" -> sane if <=15 (or unset == default 5)
-func SudoTimestampTimeoutSane() (string, error) {
-	s := sudoersContent()
-	re := regexp.MustCompile(`(?i)^\s*Defaults\s+.*timestamp_timeout\s*=\s*(-?\d+)\b`)
-	m := re.FindStringSubmatch(s)
-	if len(m) == 0 {
-		// treat "unset" as sane (CIS allows small default)
-		return "true", nil
-	}
-	v, err := strconv.Atoi(m[1])
+// factSudoTimestampTimeoutSane: an unset timestamp_timeout keeps sudo's
+// default of 5 minutes; a negative one never expires.
+func factSudoTimestampTimeoutSane(req FactRequest) (string, string, error) {
+	cfg, err := sudoersConfig(req)
 	if err != nil {
-		return "false", nil
+		return "", "", err
 	}
-	if v <= 15 {
-		return "true", nil
+	d, ok := cfg.Default("timestamp_timeout")
+	if !ok {
+		return "true", "timestamp_timeout not set; sudo default 5 minutes", nil
 	}
-	return "false", nil
+	ev := fmt.Sprintf("timestamp_timeout=%s (%s:%d)", d.Value, d.File, d.Line)
+	v, err := strconv.ParseFloat(d.Value, 64)
+	if err != nil || v < 0 || v > 15 {
+		return "false", ev, nil
+	}
+	return "true", ev, nil
 }
 
-// Forbid any "NOPASSWD: ALL" (or wildcards) on Cmnd_Alias or ALL
-func SudoNoPasswdWildcardForbidden() (string, error) {
-	s := sudoersContent()
-	// very simple heuristic: if NOPASSWD and ALL appear on same line, we consider it forbidden
-	lines := strings.Split(s, "\n")
-	for _, ln := range lines {
-		l := strings.ToLower(strings.TrimSpace(ln))
-		if strings.HasPrefix(l, "#") || l == "" {
+// factSudoNoPasswdWildcardForbidden looks for NOPASSWD on the command ALL,
+// after alias expansion, for any local account the grant names; NOPASSWD
+// on a named command is allowed.
+func factSudoNoPasswdWildcardForbidden(req FactRequest) (string, string, error) {
+	cfg, err := sudoersConfig(req)
+	if err != nil {
+		return "", "", err
+	}
+	users, err := sudoUsers(req)
+	if err != nil {
+		return "", "", err
+	}
+	for _, g := range cfg.Grants {
+		if g.Command != "ALL" || g.Negated {
 			continue
 		}
-		if strings.Contains(l, "nopasswd") && (strings.Contains(l, " ALL") || strings.Contains(l, " all")) {
-			return "false", nil
+		if names, applies, nopasswd := cfg.NoPasswdUsers(g, users); nopasswd {
+			return "false", noPasswdGrant(g, names, applies), nil
 		}
 	}
-	return "true", nil
+	return "true", fmt.Sprintf("no NOPASSWD grant of ALL in %d sudoers file(s)", len(cfg.Files)), nil
 }
 
-func readSudoers() string {
-	paths := []string{"/etc/sudoers"}
-	dir := "/etc/sudoers.d"
-	if ents, err := os.ReadDir(dir); err == nil {
-		for _, e := range ents {
-			if !e.IsDir() {
-				paths = append(paths, filepath.Join(dir, e.Name()))
+// factSudoAnyCommandUsers lists the local non-root accounts whose grants let
+// them run any command as root (without a password when nopasswd), with the
+// deciding grant in the evidence.
+func factSudoAnyCommandUsers(nopasswd bool) func(FactRequest) (Value, string, error) {
+	return func(req FactRequest) (Value, string, error) {
+		cfg, err := sudoersConfig(req)
+		if err != nil {
+			return Value{}, "", err
+		}
+		users, err := sudoUsers(req)
+		if err != nil {
+			return Value{}, "", err
+		}
+		var names, ev []string
+		for _, u := range users {
+			g, ok := cfg.AnyCommand(u)
+			if !ok || (nopasswd && !cfg.NoPasswd(g, u)) {
+				continue
 			}
+			names = append(names, u.Name)
+			ev = append(ev, fmt.Sprintf("%s via %s (%s)", u.Name, g, g.Pos()))
+		}
+		if len(names) == 0 {
+			return StringList(nil), fmt.Sprintf("%d local account(s) checked against %d grant(s)", len(users), len(cfg.Grants)), nil
 		}
+		return StringList(names), strings.Join(ev, "; "), nil
+	}
+}
+
+// factSudoNoPasswdGrants lists the grants that some local account they
+// name runs without a password.
+func factSudoNoPasswdGrants(req FactRequest) (Value, string, error) {
+	cfg, err := sudoersConfig(req)
+	if err != nil {
+		return Value{}, "", err
 	}
-	var all strings.Builder
-	for _, p := range paths {
-		b, err := os.ReadFile(p)
-		if err == nil {
-			all.Write(b)
-			all.WriteByte('\n')
+	users, err := sudoUsers(req)
+	if err != nil {
+		return Value{}, "", err
+	}
+	var out []string
+	for _, g := range cfg.Grants {
+		if g.Negated {
+			continue
+		}
+		if names, applies, nopasswd := cfg.NoPasswdUsers(g, users); nopasswd {
+			out = append(out, noPasswdGrant(g, names, applies))
 		}
 	}
-	return all.String()
+	return StringList(out), fmt.Sprintf("%d of %d grant(s) need no password", len(out), len(cfg.Grants)), nil
 }
 
-func SudoUsePTY() (string, error) {
-	s := readSudoers()
-	if strings.Contains(s, "Defaults") && strings.Contains(s, "use_pty") {
-		return "true", nil
+// noPasswdGrant renders g with its position and, when it names local
+// accounts, those that need no password: per-user Defaults can exempt some.
+func noPasswdGrant(g SudoersGrant, names []string, applies bool) string {
+	if !applies {
+		return fmt.Sprintf("%s (%s)", g, g.Pos())
 	}
-	return "false", nil
+	return fmt.Sprintf("%s (%s) for %s", g, g.Pos(), strings.Join(names, ", "))
 }
 
-func SudoLogfile() (string, error) {
-	s := readSudoers()
-	if strings.Contains(s, "Defaults") && strings.Contains(s, "logfile=") {
-		return "true", nil
+// factSudoRootCommands lists what one user may run as root, each command
+// prefixed "!" when a grant takes it away and "NOPASSWD: " when no password
+// is asked.
+func factSudoRootCommands(req FactRequest) (Value, string, error) {
+	cfg, err := sudoersConfig(req)
+	if err != nil {
+		return Value{}, "", err
+	}
+	users, err := sudoUsers(req)
+	if err != nil {
+		return Value{}, "", err
+	}
+	for _, u := range users {
+		if u.Name != req.Param {
+			continue
+		}
+		var out, ev []string
+		for _, g := range cfg.RootGrants(u) {
+			cmd := g.Command
+			switch {
+			case g.Negated:
+				cmd = "!" + cmd
+			case cfg.NoPasswd(g, u):
+				cmd = "NOPASSWD: " + cmd
+			}
+			out = append(out, cmd)
+			ev = append(ev, g.Pos())
+		}
+		return StringList(out), fmt.Sprintf("%s (groups %s): %d grant(s) %s", u.Name, strings.Join(u.Groups, ","), len(out), strings.Join(ev, ", ")), nil
+	}
+	return Value{}, "", fmt.Errorf("no local account %q in /etc/passwd", req.Param)
+}
+
+// sudoFlag reports whether the global Defaults leave name set, i.e. the
+// last setting of it is not "!name".
+func sudoFlag(cfg *SudoersConfig, name string) (string, string) {
+	d, ok := cfg.Default(name)
+	if !ok {
+		return "false", fmt.Sprintf("no 'Defaults %s' in %d sudoers file(s)", name, len(cfg.Files))
+	}
+	pos := fmt.Sprintf("%s:%d", d.File, d.Line)
+	if d.Negated {
+		return "false", fmt.Sprintf("Defaults !%s (%s)", name, pos)
+	}
+	if d.Op != "" {
+		return "true", fmt.Sprintf("Defaults %s%s%s (%s)", name, d.Op, d.Value, pos)
 	}
-	return "false", nil
+	return "true", fmt.Sprintf("Defaults %s (%s)", name, pos)
 }
@@ -0,0 +1,181 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+)
+
+// FactStore is a scan-scoped cache shared by all workers. Facts, file reads
+// and command runs are each performed once per scan; concurrent requests for
+// the same key wait for the single in-flight collection instead of repeating
+// it (e.g. sshd_config is read once for every SSH rule).
+//
+// A nil *FactStore is valid and simply does no caching.
+type FactStore struct {
+	mu      sync.Mutex
+	entries map[string]*storeEntry
+	hits    map[string]int
+	misses  map[string]int
+}
+
+type storeEntry struct {
+	done      chan struct{}
+	val       any
+	err       error
+	cancelled bool // collection was cut short; not reused
+}
+
+// Cache kinds, used as key prefixes and in Stats.
+const (
+	kindFact = "fact"
+	kindFile = "file"
+	kindCmd  = "cmd"
+	kindDir  = "dir" // directory listings, counted with the files
+	// parsed configuration models (sshd, sudoers) built from cached reads
+	kindParsed = "parsed"
+)
+
+// NewFactStore returns an empty store for one scan.
+func NewFactStore() *FactStore {
+	return &FactStore{
+		entries: map[string]*storeEntry{},
+		hits:    map[string]int{},
+		misses:  map[string]int{},
+	}
+}
+
+// do returns the cached result for kind/key, running fn at most once.
+//
+// fn runs under the first caller's ctx. If that ctx is done before fn
+// returns, the partial result is handed to the callers already waiting but
+// dropped from the cache, and later callers collect again. Waiters stop
+// waiting when their own ctx is done. Each call counts once: a hit when it
+// gets another call's result, a miss when it runs fn.
+func (s *FactStore) do(ctx context.Context, kind, key string, fn func() (any, error)) (any, error) {
+	if s == nil {
+		return fn()
+	}
+
+	k := kind + "\x00" + key
+	for {
+		s.mu.Lock()
+		if e, ok := s.entries[k]; ok {
+			s.mu.Unlock()
+			select {
+			case <-e.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if e.cancelled && ctx.Err() == nil {
+				continue // the leader gave up; collect again
+			}
+			s.mu.Lock()
+			s.hits[kind]++
+			s.mu.Unlock()
+			return e.val, e.err
+		}
+		e := &storeEntry{done: make(chan struct{})}
+		s.entries[k] = e
+		s.misses[kind]++
+		s.mu.Unlock()
+
+		e.val, e.err = fn()
+		if ctx.Err() != nil {
+			e.cancelled = true
+			s.mu.Lock()
+			if s.entries[k] == e {
+				delete(s.entries, k)
+			}
+			s.mu.Unlock()
+		}
+		close(e.done)
+		return e.val, e.err
+	}
+}
+
+// factValue is what the store keeps per fact.
+type factValue struct {
+	observed Value
+	evidence string
+}
+
+// Fact returns the cached observed value, evidence and collection error for
+// a fact, collecting it with collect on first use.
+func (s *FactStore) Fact(ctx context.Context, name string, collect func() (Value, string, error)) (Value, string, error) {
+	v, err := s.do(ctx, kindFact, name, func() (any, error) {
+		observed, evidence, err := collect()
+		return factValue{observed, evidence}, err
+	})
+	fv, ok := v.(factValue)
+	if !ok {
+		// the caller's ctx ended while waiting on another collection
+		return Value{}, err.Error(), &CollectionError{Fact: name, Err: err}
+	}
+	return fv.observed, fv.evidence, err
+}
+
+// ReadFile returns the contents of path, reading it once per scan.
+func (s *FactStore) ReadFile(path string) ([]byte, error) {
+	v, err := s.do(context.Background(), kindFile, path, func() (any, error) {
+		return os.ReadFile(path)
+	})
+	b, _ := v.([]byte)
+	return b, err
+}
+
+// ReadDir returns the entries of dir sorted by name, listing it once per
+// scan.
+func (s *FactStore) ReadDir(dir string) ([]fs.DirEntry, error) {
+	v, err := s.do(context.Background(), kindDir, dir, func() (any, error) {
+		return os.ReadDir(dir)
+	})
+	entries, _ := v.([]fs.DirEntry)
+	return entries, err
+}
+
+// cmdOutput is what the store keeps per command line.
+type cmdOutput struct {
+	stdout, stderr string
+}
+
+// Run executes name with args once per scan and returns trimmed stdout and
+// stderr. The process is killed when ctx is done.
+func (s *FactStore) Run(ctx context.Context, name string, args ...string) (string, string, error) {
+	key := strings.Join(append([]string{name}, args...), "\x00")
+	v, err := s.do(ctx, kindCmd, key, func() (any, error) {
+		out, errOut, err := runCommand(ctx, name, args...)
+		return cmdOutput{out, errOut}, err
+	})
+	co, _ := v.(cmdOutput)
+	return co.stdout, co.stderr, err
+}
+
+// StoreStats counts cache hits and misses per kind for one scan.
+type StoreStats struct {
+	FactHits, FactMisses int
+	FileHits, FileMisses int
+	CmdHits, CmdMisses   int
+}
+
+// Stats returns a snapshot of the cache counters.
+func (s *FactStore) Stats() StoreStats {
+	if s == nil {
+		return StoreStats{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return StoreStats{
+		FactHits: s.hits[kindFact], FactMisses: s.misses[kindFact],
+		FileHits: s.hits[kindFile] + s.hits[kindDir], FileMisses: s.misses[kindFile] + s.misses[kindDir],
+		CmdHits: s.hits[kindCmd], CmdMisses: s.misses[kindCmd],
+	}
+}
+
+func (st StoreStats) String() string {
+	return fmt.Sprintf("facts %d collected / %d cache hits, files %d read / %d cache hits, commands %d run / %d cache hits",
+		st.FactMisses, st.FactHits, st.FileMisses, st.FileHits, st.CmdMisses, st.CmdHits)
+}
@@ -0,0 +1,531 @@
+package checks
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FixAction is one typed remediation step from a rule's fix: block. Exactly
+// one field is set; BuildFixScript renders it as idempotent shell:
+//
+//	fix:
+//	  - sshd_set: { PermitRootLogin: "no" }
+//	  - service_enable: sshd
+type FixAction struct {
+	SSHDSet        map[string]string `yaml:"sshd_set,omitempty"`       // directive → value in the redcheck sshd drop-in, checked with sshd -t, then reload sshd
+	SysctlSet      map[string]string `yaml:"sysctl_set,omitempty"`     // persisted in /etc/sysctl.d and applied
+	LoginDefsSet   map[string]string `yaml:"login_defs_set,omitempty"` // key → value in /etc/login.defs
+	FileMode       *FileModeFix      `yaml:"file_mode,omitempty"`
+	FileContent    *FileContentFix   `yaml:"file_content,omitempty"`
+	FstabOptions   *FstabFix         `yaml:"fstab_options,omitempty"`
+	SudoersDropin  *SudoersDropin    `yaml:"sudoers_dropin,omitempty"`
+	ServiceEnable  string            `yaml:"service_enable,omitempty"`
+	PackageInstall string            `yaml:"package_install,omitempty"`
+	CryptoPolicy   string            `yaml:"crypto_policy,omitempty"`
+	CryptoModule   *CryptoModule     `yaml:"crypto_subpolicy,omitempty"`
+	SSHDHostKeys   bool              `yaml:"sshd_host_keys,omitempty"` // restrict the host private keys sshd loads
+	Manual         *ManualFix        `yaml:"manual,omitempty"`
+}
+
+// FileModeFix sets the mode and optionally the ownership of a path.
+type FileModeFix struct {
+	Path  string `yaml:"path"`
+	Mode  string `yaml:"mode"` // octal, e.g. "0600"
+	Owner string `yaml:"owner,omitempty"`
+	Group string `yaml:"group,omitempty"`
+}
+
+// FileContentFix writes a file, or only creates it when OnlyIfMissing.
+type FileContentFix struct {
+	Path          string `yaml:"path"`
+	Content       string `yaml:"content"`
+	Mode          string `yaml:"mode,omitempty"`
+	OnlyIfMissing bool   `yaml:"only_if_missing,omitempty"`
+}
+
+// FstabFix adds mount options to a mount point's /etc/fstab entry and
+// remounts it.
+type FstabFix struct {
+	Mount   string   `yaml:"mount"`
+	Options []string `yaml:"options"`
+}
+
+// SudoersDropin installs /etc/sudoers.d/<name> after visudo accepts it.
+type SudoersDropin struct {
+	Name    string `yaml:"name"`
+	Content string `yaml:"content"`
+}
+
+// CryptoModule installs a crypto-policies sub-policy module and appends it
+// to the current policy, leaving the base policy and its other modules as
+// they are. Scoped directives (cipher@SSH = ...) keep it to one back-end.
+// Rerunning it is a no-op unless the module content changed.
+type CryptoModule struct {
+	Name    string `yaml:"name"` // upper case, e.g. NO-SSHWEAK
+	Content string `yaml:"content"`
+}
+
+// cryptoModulesDir holds local sub-policy modules.
+const cryptoModulesDir = "/etc/crypto-policies/policies/modules"
+
+// ManualFix is guidance for changes too risky to automate: a message and
+// an optional read-only command that lists what needs review.
+type ManualFix struct {
+	Message string         `yaml:"message"`
+	Review  *ReviewCommand `yaml:"review,omitempty"`
+}
+
+// ReviewCommand is the listing a manual fix runs: a command and its
+// arguments, each quoted in the script, with stderr discarded and the
+// output optionally filtered by the extended regex Match (grep -E). There
+// is no shell syntax, so a review cannot pipe, redirect or chain commands:
+//
+//	review: { command: sshd, args: ["-T"], match: "^(allow|deny)users " }
+//
+// A plain string of words ("getcap -r /usr/bin") is shorthand for command
+// and args.
+type ReviewCommand struct {
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args,omitempty"`
+	Match   string   `yaml:"match,omitempty"`
+}
+
+// reviewShellChars are rejected in the string form of a review, which is
+// split on spaces and never parsed by a shell.
+const reviewShellChars = "|&;<>()$`\\\"'*?[]{}#~!\n\r"
+
+// UnmarshalYAML accepts the mapping form or the plain-words string form.
+func (r *ReviewCommand) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		if strings.ContainsAny(n.Value, reviewShellChars) {
+			return fmt.Errorf("line %d: review %q uses shell syntax; give { command, args, match } instead", n.Line, n.Value)
+		}
+		f := strings.Fields(n.Value)
+		if len(f) == 0 {
+			return fmt.Errorf("line %d: empty review", n.Line)
+		}
+		*r = ReviewCommand{Command: f[0], Args: f[1:]}
+		return nil
+	}
+	type plain ReviewCommand
+	return n.Decode((*plain)(r))
+}
+
+// sshd_set writes to a drop-in that sorts before the distribution's own
+// (RHEL 9 ships 50-redhat.conf), since sshd keeps the first value it reads.
+const (
+	sshdDropinDir = "/etc/ssh/sshd_config.d"
+	sshdDropin    = sshdDropinDir + "/00-redcheck.conf"
+)
+
+var (
+	fixKeyRE    = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	fixSysctlRE = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	fixNameRE   = regexp.MustCompile(`^[A-Za-z0-9_.@+:-]+$`)
+	fixDropinRE = regexp.MustCompile(`^[A-Za-z0-9_-]+$`) // sudo skips names with dots
+	fixModeRE   = regexp.MustCompile(`^[0-7]{3,4}$`)
+	fixOptionRE = regexp.MustCompile(`^[a-z0-9_=]+$`)
+	fixCmdRE    = regexp.MustCompile(`^[A-Za-z0-9_./+][A-Za-z0-9_./+-]*$`)
+	fixModuleRE = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]*$`)
+)
+
+// kinds returns the yaml names of the action kinds set on a.
+func (a FixAction) kinds() []string {
+	var k []string
+	add := func(set bool, name string) {
+		if set {
+			k = append(k, name)
+		}
+	}
+	add(len(a.SSHDSet) > 0, "sshd_set")
+	add(len(a.SysctlSet) > 0, "sysctl_set")
+	add(len(a.LoginDefsSet) > 0, "login_defs_set")
+	add(a.FileMode != nil, "file_mode")
+	add(a.FileContent != nil, "file_content")
+	add(a.FstabOptions != nil, "fstab_options")
+	add(a.SudoersDropin != nil, "sudoers_dropin")
+	add(a.ServiceEnable != "", "service_enable")
+	add(a.PackageInstall != "", "package_install")
+	add(a.CryptoPolicy != "", "crypto_policy")
+	add(a.CryptoModule != nil, "crypto_subpolicy")
+	add(a.SSHDHostKeys, "sshd_host_keys")
+	add(a.Manual != nil, "manual")
+	return k
+}
+
+// Validate checks that exactly one action is set and that its values fit
+// the shell the action renders: keys, names, modes and options match a
+// strict pattern, values and paths are single-line without control
+// characters, and a manual review is a command name plus arguments. emit
+// single-quotes every value and path it writes into the script; content
+// is written verbatim to the target file and is not checked.
+func (a FixAction) Validate() []error {
+	var errs []error
+	bad := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+
+	switch k := a.kinds(); len(k) {
+	case 0:
+		return []error{fmt.Errorf("empty fix action")}
+	case 1:
+	default:
+		return []error{fmt.Errorf("fix action sets %s; use one per list item", strings.Join(k, " and "))}
+	}
+
+	checkMap := func(kind string, m map[string]string, keyRE *regexp.Regexp) {
+		for k, v := range m {
+			if !keyRE.MatchString(k) {
+				bad("%s: bad key %q", kind, k)
+			}
+			if v == "" || hasControl(v) {
+				bad("%s: %s needs a single-line value", kind, k)
+			}
+		}
+	}
+	checkPath := func(kind, p string) {
+		if !strings.HasPrefix(p, "/") || hasControl(p) {
+			bad("%s: path %q must be absolute, without control characters", kind, p)
+		}
+	}
+	checkName := func(kind, what, v string) {
+		if v != "" && !fixNameRE.MatchString(v) {
+			bad("%s: bad %s %q", kind, what, v)
+		}
+	}
+
+	checkMap("sshd_set", a.SSHDSet, fixKeyRE)
+	checkMap("sysctl_set", a.SysctlSet, fixSysctlRE)
+	checkMap("login_defs_set", a.LoginDefsSet, fixKeyRE)
+	if f := a.FileMode; f != nil {
+		checkPath("file_mode", f.Path)
+		if !fixModeRE.MatchString(f.Mode) {
+			bad("file_mode: mode %q is not octal", f.Mode)
+		}
+		checkName("file_mode", "owner", f.Owner)
+		checkName("file_mode", "group", f.Group)
+	}
+	if f := a.FileContent; f != nil {
+		checkPath("file_content", f.Path)
+		if f.Mode != "" && !fixModeRE.MatchString(f.Mode) {
+			bad("file_content: mode %q is not octal", f.Mode)
+		}
+	}
+	if f := a.FstabOptions; f != nil {
+		checkPath("fstab_options", f.Mount)
+		if len(f.Options) == 0 {
+			bad("fstab_options: no options")
+		}
+		for _, o := range f.Options {
+			if !fixOptionRE.MatchString(o) {
+				bad("fstab_options: bad option %q", o)
+			}
+		}
+	}
+	if d := a.SudoersDropin; d != nil {
+		if !fixDropinRE.MatchString(d.Name) {
+			bad("sudoers_dropin: name %q must be letters, digits, - and _ only", d.Name)
+		}
+		if strings.TrimSpace(d.Content) == "" {
+			bad("sudoers_dropin: empty content")
+		}
+	}
+	checkName("service_enable", "service", a.ServiceEnable)
+	checkName("package_install", "package", a.PackageInstall)
+	checkName("crypto_policy", "policy", a.CryptoPolicy)
+	if m := a.CryptoModule; m != nil {
+		if !fixModuleRE.MatchString(m.Name) {
+			bad("crypto_subpolicy: name %q must be upper-case letters, digits, - and _", m.Name)
+		}
+		if strings.TrimSpace(m.Content) == "" {
+			bad("crypto_subpolicy: empty content")
+		}
+	}
+	if m := a.Manual; m != nil {
+		if strings.TrimSpace(m.Message) == "" {
+			bad("manual: empty message")
+		}
+		if r := m.Review; r != nil {
+			if !fixCmdRE.MatchString(r.Command) {
+				bad("manual: review command %q must be a program name or path", r.Command)
+			}
+			for _, v := range append(append([]string(nil), r.Args...), r.Match) {
+				if hasControl(v) {
+					bad("manual: review arguments and match must be single-line")
+					break
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// emitFix writes the shell for a rule's fix actions. It returns false when
+// there are none.
+func emitFix(w io.Writer, actions []FixAction) bool {
+	if len(actions) == 0 {
+		fmt.Fprintln(w, `  echo "[INFO] No automatic remediation implemented for this rule yet."`)
+		fmt.Fprintln(w, `  echo "       Please follow the guidance from the redcheck report manually."`)
+		return false
+	}
+	for _, a := range actions {
+		if errs := a.Validate(); len(errs) > 0 {
+			fmt.Fprintf(w, "  echo %s\n", shQuote(fmt.Sprintf("[WARN] invalid fix action skipped: %v", errs[0])))
+			continue
+		}
+		a.emit(w)
+	}
+	return true
+}
+
+func (a FixAction) emit(w io.Writer) {
+	line := func(format string, args ...any) { fmt.Fprintf(w, "  "+format+"\n", args...) }
+
+	switch {
+	case len(a.SSHDSet) > 0:
+		cfg, dropin := SSHDConfigPath, sshdDropin
+		bak := dropin + ".redcheck-bak" // not *.conf, so sshd ignores it
+		cfgBak := cfg + ".redcheck-bak"
+		line("if [ -f %s ]; then", cfg)
+		// the drop-in only counts if sshd_config includes the directory
+		// before it sets the keyword itself
+		line("  rm -f %s", cfgBak)
+		line("  if ! grep -qiE %s %s; then", shQuote(`^\s*Include\s+(/etc/ssh/)?sshd_config\.d/`), cfg)
+		line("    cp -p %s %s", cfg, cfgBak)
+		line("    sed -i '1i Include %s/*.conf' %s", sshdDropinDir, cfg)
+		line(`    echo " -> Added 'Include %s/*.conf' to %s"`, sshdDropinDir, cfg)
+		line("  fi")
+		line("  mkdir -p %s", sshdDropinDir)
+		line("  if [ -f %s ]; then cp -p %s %s; else rm -f %s; fi", dropin, dropin, bak, bak)
+		line("  touch %s && chmod 600 %s", dropin, dropin)
+		for _, k := range sortedKeys(a.SSHDSet) {
+			setDirective(w, dropin, k, k+" "+a.SSHDSet[k])
+		}
+		line("  if sshd -t; then")
+		line("    rm -f %s %s", bak, cfgBak)
+		line("    systemctl reload sshd 2>/dev/null || systemctl reload ssh 2>/dev/null || true")
+		line("    if sshd -T >/dev/null 2>&1; then")
+		for _, k := range sortedKeys(a.SSHDSet) {
+			want := k + " " + a.SSHDSet[k]
+			line("      sshd -T | grep -qixF %s || echo %s", shQuote(want),
+				shQuote("[WARN] sshd -T does not show '"+want+"'; an earlier line in "+cfg+" or another drop-in still sets "+k+"."))
+		}
+		line("    fi")
+		line("  else")
+		line("    if [ -f %s ]; then mv -f %s %s; else rm -f %s; fi", bak, bak, dropin, dropin)
+		line("    if [ -f %s ]; then mv -f %s %s; fi", cfgBak, cfgBak, cfg)
+		line(`    echo "[WARN] sshd -t rejected the configuration; %s and %s restored and sshd not reloaded."`, cfg, dropin)
+		line("  fi")
+		line("else")
+		line(`  echo "[WARN] %s not found; adjust SSH configuration manually."`, cfg)
+		line("fi")
+
+	case len(a.LoginDefsSet) > 0:
+		const cfg = "/etc/login.defs"
+		line("if [ -f %s ]; then", cfg)
+		for _, k := range sortedKeys(a.LoginDefsSet) {
+			setDirective(w, cfg, k, k+" "+a.LoginDefsSet[k])
+		}
+		line("else")
+		line(`  echo "[WARN] %s not found; set the values manually."`, cfg)
+		line("fi")
+
+	case len(a.SysctlSet) > 0:
+		const cfg = "/etc/sysctl.d/60-redcheck.conf"
+		line("touch %s", cfg)
+		for _, k := range sortedKeys(a.SysctlSet) {
+			setDirective(w, cfg, k, k+" = "+a.SysctlSet[k])
+			line("sysctl -w %s >/dev/null || echo %s", shQuote(k+"="+a.SysctlSet[k]), shQuote("[WARN] sysctl -w "+k+" failed"))
+		}
+
+	case a.FileMode != nil:
+		f := a.FileMode
+		p := shQuote(f.Path)
+		line("echo %s", shQuote(" -> Setting mode "+f.Mode+" on "+f.Path+"..."))
+		line("if [ -e %s ]; then", p)
+		if f.Owner != "" {
+			line("  chown %s %s", shQuote(f.Owner), p)
+		}
+		if f.Group != "" {
+			line("  chgrp %s %s", shQuote(f.Group), p)
+		}
+		line("  chmod %s %s", f.Mode, p)
+		line("else")
+		line("  echo %s", shQuote("[WARN] "+f.Path+" not found"))
+		line("fi")
+
+	case a.FileContent != nil:
+		f := a.FileContent
+		p := shQuote(f.Path)
+		if f.OnlyIfMissing {
+			line("if [ ! -e %s ]; then", p)
+		} else {
+			line("if true; then")
+		}
+		line("  echo %s", shQuote(" -> Writing "+f.Path+"..."))
+		line("  printf '%%s\\n' %s > %s", shQuote(strings.TrimRight(f.Content, "\n")), p)
+		line("fi")
+		if f.Mode != "" {
+			line("chmod %s %s", f.Mode, p)
+		}
+
+	case a.FstabOptions != nil:
+		f := a.FstabOptions
+		m := shQuote(f.Mount)
+		opts := strings.Join(f.Options, ",")
+		line("echo %s", shQuote(" -> Adding "+opts+" to the "+f.Mount+" entry in /etc/fstab..."))
+		line(`if awk -v m=%s '$1 !~ /^#/ && $2 == m {f=1} END {exit !f}' /etc/fstab; then`, m)
+		line(`  awk -v m=%s -v add=%s 'BEGIN {OFS="\t"} $1 !~ /^#/ && $2 == m { n = split(add, o, ","); for (i = 1; i <= n; i++) if (("," $4 ",") !~ ("," o[i] ",")) $4 = $4 "," o[i] } {print}' /etc/fstab > /etc/fstab.redcheck`, m, shQuote(opts))
+		line("  cat /etc/fstab.redcheck > /etc/fstab && rm -f /etc/fstab.redcheck")
+		line("  mount -o remount %s || echo %s", m, shQuote("[WARN] remount of "+f.Mount+" failed; reboot to apply"))
+		line("else")
+		line("  echo %s", shQuote("[WARN] "+f.Mount+" has no /etc/fstab entry; add one with "+opts+" manually."))
+		line("fi")
+
+	case a.SudoersDropin != nil:
+		d := a.SudoersDropin
+		dst := "/etc/sudoers.d/" + d.Name
+		tmp := dst + ".redcheck" // sudo ignores names containing a dot
+		line(`echo " -> Installing %s..."`, dst)
+		line("if [ -d /etc/sudoers.d ]; then")
+		line("  printf '%%s\\n' %s > %s", shQuote(strings.TrimRight(d.Content, "\n")), tmp)
+		line("  chmod 440 %s", tmp)
+		line("  if visudo -cf %s >/dev/null 2>&1; then", tmp)
+		line("    mv -f %s %s", tmp, dst)
+		line("  else")
+		line("    rm -f %s", tmp)
+		line(`    echo "[WARN] visudo rejected %s; not installed."`, dst)
+		line("  fi")
+		line("else")
+		line(`  echo "[WARN] /etc/sudoers.d not present; configure sudoers manually with visudo."`)
+		line("fi")
+
+	case a.ServiceEnable != "":
+		s := shQuote(a.ServiceEnable)
+		line(`echo " -> Enabling and starting %s..."`, a.ServiceEnable)
+		line("systemctl enable --now %s || echo %s", s, shQuote("[WARN] Failed to enable/start "+a.ServiceEnable+"; investigate manually."))
+
+	case a.PackageInstall != "":
+		p := shQuote(a.PackageInstall)
+		line(`echo " -> Installing %s using common package managers (dnf/yum/apt)..."`, a.PackageInstall)
+		line("if command -v dnf >/dev/null 2>&1; then")
+		line("  dnf install -y %s || echo \"[WARN] dnf install %s failed\"", p, a.PackageInstall)
+		line("elif command -v yum >/dev/null 2>&1; then")
+		line("  yum install -y %s || echo \"[WARN] yum install %s failed\"", p, a.PackageInstall)
+		line("elif command -v apt-get >/dev/null 2>&1; then")
+		line("  apt-get update && apt-get install -y %s || echo \"[WARN] apt-get install %s failed\"", p, a.PackageInstall)
+		line("else")
+		line("  echo \"[WARN] Unsupported package manager; install %s manually.\"", a.PackageInstall)
+		line("fi")
+
+	case a.CryptoPolicy != "":
+		line(`echo " -> Setting system crypto policy to %s..."`, a.CryptoPolicy)
+		line("if command -v update-crypto-policies >/dev/null 2>&1; then")
+		line("  update-crypto-policies --set %s || echo \"[WARN] update-crypto-policies failed\"", shQuote(a.CryptoPolicy))
+		line("else")
+		line("  echo \"[WARN] update-crypto-policies not found; configure crypto policy manually.\"")
+		line("fi")
+
+	case a.CryptoModule != nil:
+		m := a.CryptoModule
+		mod := cryptoModulesDir + "/" + m.Name + ".pmod"
+		tmp := mod + ".redcheck"
+		line(`echo " -> Adding crypto sub-policy %s to the current policy..."`, m.Name)
+		line("if command -v update-crypto-policies >/dev/null 2>&1; then")
+		line("  mkdir -p %s", cryptoModulesDir)
+		line("  printf '%%s\\n' %s > %s", shQuote(strings.TrimRight(m.Content, "\n")), tmp)
+		line("  rc_changed=")
+		line("  if cmp -s %s %s; then rm -f %s; else mv -f %s %s && chmod 644 %s; rc_changed=1; fi", tmp, mod, tmp, tmp, mod, mod)
+		line("  rc_policy=$(update-crypto-policies --show 2>/dev/null || echo DEFAULT)")
+		line(`  case ":$rc_policy:" in`)
+		line(`    *:%s:*) rc_want=$rc_policy ;;`, m.Name)
+		line(`    *) rc_want="$rc_policy:%s" ;;`, m.Name)
+		line("  esac")
+		line(`  if [ "$rc_want" != "$rc_policy" ] || [ -n "$rc_changed" ]; then`)
+		line(`    update-crypto-policies --set "$rc_want" || echo "[WARN] update-crypto-policies failed"`)
+		line("  else")
+		line(`    echo "    $rc_policy already includes %s; nothing to do."`, m.Name)
+		line("  fi")
+		line("else")
+		line("  echo \"[WARN] update-crypto-policies not found; configure crypto policy manually.\"")
+		line("fi")
+
+	case a.SSHDHostKeys:
+		// the keys ssh.host_keys_loose checks: sshd -T's HostKey lines, those
+		// of sshd_config, or the default names. Keys of group ssh_keys may stay group-readable.
+		line(`echo " -> Restricting the host private keys sshd loads..."`)
+		line(`rc_keys=$(sshd -T 2>/dev/null | awk '$1 == "hostkey" {print $2}' || true)`)
+		line(`[ -n "$rc_keys" ] || rc_keys=$(awk 'tolower($1) == "hostkey" {print $2}' %s 2>/dev/null || true)`, SSHDConfigPath)
+		line(`[ -n "$rc_keys" ] || rc_keys=$(ls /etc/ssh/ssh_host_*_key 2>/dev/null || true)`)
+		line(`for rc_key in $rc_keys; do`)
+		line(`  [ -f "$rc_key" ] || continue`)
+		line(`  chown root "$rc_key"`)
+		line(`  if [ "$(stat -c %%G "$rc_key")" = ssh_keys ]; then`)
+		line(`    chmod u-xs,g-wxs,o-rwx,-t "$rc_key"`)
+		line("  else")
+		line(`    chgrp root "$rc_key" && chmod u-xs,go-rwx,-t "$rc_key"`)
+		line("  fi")
+		line(`  echo "    $(stat -c '%%a %%U:%%G %%n' "$rc_key")"`)
+		line("done")
+
+	case a.Manual != nil:
+		line("echo %s", shQuote("[CAUTION] "+a.Manual.Message))
+		if r := a.Manual.Review; r != nil {
+			argv := []string{r.Command}
+			for _, arg := range r.Args {
+				argv = append(argv, shQuote(arg))
+			}
+			if r.Match != "" {
+				line("%s 2>/dev/null | grep -E %s || true", strings.Join(argv, " "), shQuote(r.Match))
+			} else {
+				line("%s 2>/dev/null || true", strings.Join(argv, " "))
+			}
+		}
+	}
+}
+
+// setDirective replaces the line setting key in file with value, or
+// appends value when key is not set. Keys match case-insensitively, as
+// sshd and login.defs treat them.
+func setDirective(w io.Writer, file, key, value string) {
+	re := `^\s*` + regexp.QuoteMeta(key) + `(\s|=)`
+	fmt.Fprintf(w, "    echo %s\n", shQuote(" -> "+value+" in "+file))
+	fmt.Fprintf(w, "    if grep -qiE %s %s; then\n", shQuote(re), file)
+	fmt.Fprintf(w, "      sed -i -E %s %s\n", shQuote("s/"+re+".*/"+sedEscape(value)+"/I"), file)
+	fmt.Fprintln(w, "    else")
+	fmt.Fprintf(w, "      printf '%%s\\n' %s >> %s\n", shQuote(value), file)
+	fmt.Fprintln(w, "    fi")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hasControl reports whether s holds a control character (newline, NUL,
+// escape, ...) other than tab.
+func hasControl(s string) bool {
+	for _, r := range s {
+		if r < 0x20 && r != '\t' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// shQuote single-quotes s for the shell.
+func shQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// sedEscape escapes s for the replacement side of a sed s/// command.
+func sedEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `/`, `\/`, `&`, `\&`).Replace(s)
+}
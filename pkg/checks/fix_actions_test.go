@@ -0,0 +1,205 @@
+package checks
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestFixActionValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		action FixAction
+		ok     bool
+	}{
+		{"sshd_set", FixAction{SSHDSet: map[string]string{"PermitRootLogin": "no"}}, true},
+		{"sshd_set value with shell syntax", FixAction{SSHDSet: map[string]string{"Banner": "$(id)"}}, true}, // quoted on output
+		{"sshd_set bad key", FixAction{SSHDSet: map[string]string{"Permit Root": "no"}}, false},
+		{"sshd_set multi-line value", FixAction{SSHDSet: map[string]string{"Banner": "a\nMatch all"}}, false},
+		{"sshd_set control character", FixAction{SSHDSet: map[string]string{"Banner": "a\x1b[2J"}}, false},
+		{"sysctl_set", FixAction{SysctlSet: map[string]string{"net.ipv4.ip_forward": "0"}}, true},
+		{"sysctl_set bad key", FixAction{SysctlSet: map[string]string{"net/ipv4;id": "0"}}, false},
+		{"file_mode", FixAction{FileMode: &FileModeFix{Path: "/etc/shadow", Mode: "0000", Owner: "root", Group: "root"}}, true},
+		{"file_mode relative path", FixAction{FileMode: &FileModeFix{Path: "etc/shadow", Mode: "0000"}}, false},
+		{"file_mode path with newline", FixAction{FileMode: &FileModeFix{Path: "/tmp/x\nid", Mode: "0600"}}, false},
+		{"file_mode mode", FixAction{FileMode: &FileModeFix{Path: "/etc/shadow", Mode: "u=rw"}}, false},
+		{"file_mode owner", FixAction{FileMode: &FileModeFix{Path: "/etc/shadow", Mode: "0600", Owner: "root;id"}}, false},
+		{"file_content", FixAction{FileContent: &FileContentFix{Path: "/etc/issue", Content: "$(not run)", Mode: "0644"}}, true},
+		{"fstab_options", FixAction{FstabOptions: &FstabFix{Mount: "/tmp", Options: []string{"nodev", "nosuid"}}}, true},
+		{"fstab_options none", FixAction{FstabOptions: &FstabFix{Mount: "/tmp"}}, false},
+		{"fstab_options bad option", FixAction{FstabOptions: &FstabFix{Mount: "/tmp", Options: []string{"nodev,exec"}}}, false},
+		{"sudoers_dropin", FixAction{SudoersDropin: &SudoersDropin{Name: "use-pty", Content: "Defaults use_pty"}}, true},
+		{"sudoers_dropin dotted name", FixAction{SudoersDropin: &SudoersDropin{Name: "use.pty", Content: "Defaults use_pty"}}, false},
+		{"sudoers_dropin empty", FixAction{SudoersDropin: &SudoersDropin{Name: "use-pty"}}, false},
+		{"service_enable", FixAction{ServiceEnable: "auditd"}, true},
+		{"service_enable shell", FixAction{ServiceEnable: "auditd; id"}, false},
+		{"crypto_policy", FixAction{CryptoPolicy: "DEFAULT:NO-SHA1"}, true},
+		{"crypto_subpolicy", FixAction{CryptoModule: &CryptoModule{Name: "NO-SSHWEAK", Content: "cipher@SSH = -*-CBC"}}, true},
+		{"crypto_subpolicy lower case", FixAction{CryptoModule: &CryptoModule{Name: "no-sshweak", Content: "x"}}, false},
+		{"crypto_subpolicy empty", FixAction{CryptoModule: &CryptoModule{Name: "NO-SSHWEAK"}}, false},
+		{"sshd_host_keys", FixAction{SSHDHostKeys: true}, true},
+		{"manual", FixAction{Manual: &ManualFix{Message: "Review it.", Review: &ReviewCommand{Command: "getcap", Args: []string{"-r", "/usr/bin"}}}}, true},
+		{"manual empty message", FixAction{Manual: &ManualFix{}}, false},
+		{"manual review command", FixAction{Manual: &ManualFix{Message: "x", Review: &ReviewCommand{Command: "id;sh"}}}, false},
+		{"manual review multi-line arg", FixAction{Manual: &ManualFix{Message: "x", Review: &ReviewCommand{Command: "ls", Args: []string{"a\nid"}}}}, false},
+		{"empty", FixAction{}, false},
+		{"two kinds", FixAction{ServiceEnable: "auditd", PackageInstall: "audit"}, false},
+	}
+	for _, tt := range tests {
+		errs := tt.action.Validate()
+		if (len(errs) == 0) != tt.ok {
+			t.Errorf("%s: Validate() = %v, want ok=%v", tt.name, errs, tt.ok)
+		}
+	}
+}
+
+func TestFixActionEmit(t *testing.T) {
+	tests := []struct {
+		name   string
+		action FixAction
+		want   []string // lines the rendered shell must contain
+	}{
+		{"sshd_set", FixAction{SSHDSet: map[string]string{"PermitRootLogin": "no"}}, []string{
+			"cp -p /etc/ssh/sshd_config /etc/ssh/sshd_config.redcheck-bak",
+			"printf '%s\\n' 'PermitRootLogin no' >> /etc/ssh/sshd_config.d/00-redcheck.conf",
+			"if sshd -t; then",
+			"if [ -f /etc/ssh/sshd_config.redcheck-bak ]; then mv -f /etc/ssh/sshd_config.redcheck-bak /etc/ssh/sshd_config; fi",
+		}},
+		{"sysctl_set", FixAction{SysctlSet: map[string]string{"net.ipv4.ip_forward": "0"}}, []string{
+			"printf '%s\\n' 'net.ipv4.ip_forward = 0' >> /etc/sysctl.d/60-redcheck.conf",
+			"sysctl -w 'net.ipv4.ip_forward=0' >/dev/null",
+		}},
+		{"file_mode", FixAction{FileMode: &FileModeFix{Path: "/etc/shadow", Mode: "0000", Owner: "root", Group: "root"}}, []string{
+			"echo ' -> Setting mode 0000 on /etc/shadow...'",
+			"chown 'root' '/etc/shadow'",
+			"chmod 0000 '/etc/shadow'",
+		}},
+		{"file_content only if missing", FixAction{FileContent: &FileContentFix{Path: "/etc/issue", Content: "Authorized use only\n", OnlyIfMissing: true}}, []string{
+			"if [ ! -e '/etc/issue' ]; then",
+			"printf '%s\\n' 'Authorized use only' > '/etc/issue'",
+		}},
+		{"fstab_options", FixAction{FstabOptions: &FstabFix{Mount: "/tmp", Options: []string{"nodev", "nosuid"}}}, []string{
+			"echo ' -> Adding nodev,nosuid to the /tmp entry in /etc/fstab...'",
+			"mount -o remount '/tmp'",
+		}},
+		{"sudoers_dropin", FixAction{SudoersDropin: &SudoersDropin{Name: "use-pty", Content: "Defaults use_pty"}}, []string{
+			"printf '%s\\n' 'Defaults use_pty' > /etc/sudoers.d/use-pty.redcheck",
+			"if visudo -cf /etc/sudoers.d/use-pty.redcheck >/dev/null 2>&1; then",
+			"mv -f /etc/sudoers.d/use-pty.redcheck /etc/sudoers.d/use-pty",
+		}},
+		{"crypto_subpolicy", FixAction{CryptoModule: &CryptoModule{Name: "NO-SSHWEAK", Content: "cipher@SSH = -*-CBC\n"}}, []string{
+			"printf '%s\\n' 'cipher@SSH = -*-CBC' > /etc/crypto-policies/policies/modules/NO-SSHWEAK.pmod.redcheck",
+			`*) rc_want="$rc_policy:NO-SSHWEAK" ;;`,
+		}},
+		{"manual", FixAction{Manual: &ManualFix{Message: "Review grants.", Review: &ReviewCommand{Command: "sshd", Args: []string{"-T"}, Match: "^allowgroups "}}}, []string{
+			"echo '[CAUTION] Review grants.'",
+			"sshd '-T' 2>/dev/null | grep -E '^allowgroups ' || true",
+		}},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		tt.action.emit(&b)
+		for _, want := range tt.want {
+			if !strings.Contains(b.String(), want) {
+				t.Errorf("%s: rendered shell lacks %q:\n%s", tt.name, want, b.String())
+			}
+		}
+		checkShellSyntax(t, tt.name, b.String())
+	}
+}
+
+// TestBuildFixScriptQuoting feeds command substitutions through every
+// rule field and action value that reaches the script: none may be left
+// where the shell would run it.
+func TestBuildFixScriptQuoting(t *testing.T) {
+	const dollar, tick = "$(touch /tmp/pwned)", "`touch /tmp/pwned`"
+	var results []CheckResult
+	for i, payload := range []string{dollar, tick, "'" + dollar + "'", `"` + dollar + `"`} {
+		results = append(results, CheckResult{
+			ID:          "SITE-" + string(rune('1'+i)) + payload,
+			Title:       "title " + payload,
+			Category:    "cat " + payload,
+			Status:      "fail",
+			Expected:    "exp " + payload,
+			Remediation: "fix " + payload,
+			Fix: []FixAction{
+				{SSHDSet: map[string]string{"Banner": payload}},
+				{LoginDefsSet: map[string]string{"UMASK": payload}},
+				{SysctlSet: map[string]string{"kernel.x": payload}},
+				{FileMode: &FileModeFix{Path: "/tmp/" + payload, Mode: "0600"}},
+				{FileContent: &FileContentFix{Path: "/tmp/" + payload, Content: payload}},
+				{FstabOptions: &FstabFix{Mount: "/tmp/" + payload, Options: []string{"nodev"}}},
+				{Manual: &ManualFix{Message: payload, Review: &ReviewCommand{Command: "ls", Args: []string{payload}, Match: payload}}},
+			},
+		})
+	}
+	var b bytes.Buffer
+	if err := BuildFixScript(results, &b); err != nil {
+		t.Fatal(err)
+	}
+	script := b.String()
+	for _, marker := range []string{dollar, tick} {
+		for off := 0; ; {
+			i := strings.Index(script[off:], marker)
+			if i < 0 {
+				break
+			}
+			i += off
+			if !shellInert(script, i) {
+				t.Errorf("%q left live at %q", marker, lineAt(script, i))
+			}
+			off = i + 1
+		}
+	}
+	checkShellSyntax(t, "hostile script", script)
+}
+
+// shellInert reports whether the character at pos of script is literal:
+// inside single quotes, or escaped by a backslash elsewhere.
+func shellInert(script string, pos int) bool {
+	var quote byte
+	for i := 0; i < pos; i++ {
+		switch ch := script[i]; {
+		case quote == '\'':
+			if ch == '\'' {
+				quote = 0
+			}
+		case ch == '\\':
+			if i+1 == pos {
+				return true
+			}
+			i++
+		case quote == '"':
+			if ch == '"' {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		}
+	}
+	return quote == '\''
+}
+
+func lineAt(s string, pos int) string {
+	start := strings.LastIndexByte(s[:pos], '\n') + 1
+	end := strings.IndexByte(s[pos:], '\n')
+	if end < 0 {
+		return s[start:]
+	}
+	return s[start : pos+end]
+}
+
+// checkShellSyntax runs bash -n over the script when bash is installed.
+func checkShellSyntax(t *testing.T, name, script string) {
+	t.Helper()
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(bash, "-n")
+	cmd.Stdin = strings.NewReader(script)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("%s: bash -n: %v\n%s", name, err, out)
+	}
+}
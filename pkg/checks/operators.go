@@ -0,0 +1,208 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// operatorAliases lets rule authors write the symbolic form of an operator.
+var operatorAliases = map[string]string{
+	"==": "eq",
+	"!=": "ne",
+	"=~": "regex",
+	"<":  "lt",
+	"<=": "le",
+	">":  "gt",
+	">=": "ge",
+}
+
+// normalizeOperator lower-cases op and resolves symbolic aliases, including
+// the spelled-out count forms ("count <= 3" is written operator "count <=").
+func normalizeOperator(op string) string {
+	op = strings.ToLower(strings.TrimSpace(op))
+	if rest, ok := strings.CutPrefix(op, "count"); ok && !strings.HasPrefix(rest, "_") {
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			return "count_eq"
+		}
+		if alias, ok := operatorAliases[rest]; ok {
+			return "count_" + alias
+		}
+		return op
+	}
+	if alias, ok := operatorAliases[op]; ok {
+		return alias
+	}
+	return op
+}
+
+// evalOperator compares observed against the expectation described by op,
+// expected and values. It returns whether the condition holds, a short
+// human-readable form of the expectation (e.g. "<= 365", "in {no, yes}")
+// and, for list and map facts, the elements that broke it. Only operators
+// that single out elements (empty, not_contains, in/not_in, all_match,
+// none_match) return offenders; a failed count or comparison names none.
+//
+// Scalar operators compare the value's string form, so list facts keep
+// their comma-joined meaning there; contains, in/not_in and the count and
+// *_match operators work element by element.
+//
+// An error is returned only when the rule itself is malformed (unknown
+// operator, bad regex, non-integer bound); a non-integer observed value for a
+// numeric operator is simply a failed condition.
+func evalOperator(op string, observed Value, expected string, values []string) (bool, string, []string, error) {
+	op = normalizeOperator(op)
+	str := observed.String()
+
+	switch op {
+	case "eq":
+		return str == expected, expected, nil, nil
+	case "ne":
+		return str != expected, "!= " + expected, nil, nil
+
+	case "contains", "not_contains":
+		found := false
+		if observed.IsCollection() {
+			for _, item := range observed.Items() {
+				key, _, _ := strings.Cut(item, "=")
+				if item == expected || (observed.Kind() == KindMap && key == expected) {
+					found = true
+					break
+				}
+			}
+		} else {
+			found = strings.Contains(str, expected)
+		}
+		if op == "contains" {
+			return found, "contains " + expected, nil, nil
+		}
+		var offenders []string
+		if found && observed.IsCollection() {
+			offenders = []string{expected}
+		}
+		return !found, "not contains " + expected, offenders, nil
+
+	case "empty":
+		// every element of a list that should be empty is an offender
+		var offenders []string
+		if observed.IsCollection() {
+			offenders = observed.Items()
+		}
+		return observed.Empty(), "empty", offenders, nil
+	case "not_empty":
+		return !observed.Empty(), "not empty", nil, nil
+
+	case "regex", "not_regex":
+		re, err := regexp.Compile(expected)
+		if err != nil {
+			return false, expected, nil, fmt.Errorf("invalid regex %q: %w", expected, err)
+		}
+		if op == "regex" {
+			return re.MatchString(str), "matches /" + expected + "/", nil, nil
+		}
+		return !re.MatchString(str), "not matches /" + expected + "/", nil, nil
+
+	case "all_match", "any_match", "none_match":
+		re, err := regexp.Compile(expected)
+		if err != nil {
+			return false, expected, nil, fmt.Errorf("invalid regex %q: %w", expected, err)
+		}
+		var matched, unmatched []string
+		for _, item := range observed.Items() {
+			if re.MatchString(item) {
+				matched = append(matched, item)
+			} else {
+				unmatched = append(unmatched, item)
+			}
+		}
+		desc := strings.Replace(op, "_", " ", 1) + " /" + expected + "/"
+		switch op {
+		case "all_match":
+			return len(unmatched) == 0, desc, unmatched, nil
+		case "none_match":
+			return len(matched) == 0, desc, matched, nil
+		}
+		return len(matched) > 0, desc, nil, nil
+
+	case "in", "not_in":
+		set := operatorValues(expected, values)
+		member := func(s string) bool {
+			for _, v := range set {
+				if s == v {
+					return true
+				}
+			}
+			return false
+		}
+		desc := "{" + strings.Join(set, ", ") + "}"
+		if observed.IsCollection() {
+			// every element must (not) be in the set
+			var offenders []string
+			for _, item := range observed.Items() {
+				if member(item) != (op == "in") {
+					offenders = append(offenders, item)
+				}
+			}
+			if op == "in" {
+				return len(offenders) == 0, "all in " + desc, offenders, nil
+			}
+			return len(offenders) == 0, "none in " + desc, offenders, nil
+		}
+		if op == "in" {
+			return member(str), "in " + desc, nil, nil
+		}
+		return !member(str), "not in " + desc, nil, nil
+
+	case "lt", "le", "gt", "ge", "count_eq", "count_ne", "count_lt", "count_le", "count_gt", "count_ge":
+		cmp, counting := strings.CutPrefix(op, "count_")
+		symbol := map[string]string{"eq": "==", "ne": "!=", "lt": "<", "le": "<=", "gt": ">", "ge": ">="}[cmp]
+		desc := symbol + " " + expected
+		if counting {
+			desc = "count " + desc
+		}
+		bound, err := strconv.ParseInt(strings.TrimSpace(expected), 10, 64)
+		if err != nil {
+			return false, desc, nil, fmt.Errorf("operator %s needs an integer bound, got %q", op, expected)
+		}
+		n, ok := observed.Int()
+		if counting {
+			n, ok = int64(observed.Len()), true
+		}
+		if !ok {
+			return false, desc, nil, nil
+		}
+		switch cmp {
+		case "eq":
+			return n == bound, desc, nil, nil
+		case "ne":
+			return n != bound, desc, nil, nil
+		case "lt":
+			return n < bound, desc, nil, nil
+		case "le":
+			return n <= bound, desc, nil, nil
+		case "gt":
+			return n > bound, desc, nil, nil
+		default:
+			return n >= bound, desc, nil, nil
+		}
+	}
+
+	return false, expected, nil, fmt.Errorf("unknown operator %q", op)
+}
+
+// operatorValues returns the set used by in/not_in: the explicit values list,
+// or the comma-separated expected string when no list was given.
+func operatorValues(expected string, values []string) []string {
+	if len(values) > 0 {
+		return values
+	}
+	var out []string
+	for _, v := range strings.Split(expected, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
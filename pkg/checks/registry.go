@@ -0,0 +1,225 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FactRequest is what a collector receives for one fact.
+type FactRequest struct {
+	// Fact is the full fact name, e.g. "sysctl:net.ipv4.ip_forward".
+	Fact string
+	// Param is the text after a family prefix ("net.ipv4.ip_forward");
+	// empty for plain facts.
+	Param string
+	// Timeout is the per-rule budget for collectors that run commands.
+	Timeout time.Duration
+	// Ctx is cancelled when the rule times out or the scan is interrupted;
+	// long-running collectors (walks, commands) must stop when it is done.
+	Ctx context.Context
+
+	store *FactStore
+}
+
+// Context returns req.Ctx, or context.Background() if none was set.
+func (r FactRequest) Context() context.Context {
+	if r.Ctx == nil {
+		return context.Background()
+	}
+	return r.Ctx
+}
+
+// ReadFile reads path through the scan's fact store, so every collector
+// asking for the same file shares one read.
+func (r FactRequest) ReadFile(path string) ([]byte, error) {
+	return r.store.ReadFile(path)
+}
+
+// ReadDir lists dir through the scan's fact store, so Include and
+// includedir expansion sees the same tree as ReadFile.
+func (r FactRequest) ReadDir(dir string) ([]fs.DirEntry, error) {
+	return r.store.ReadDir(dir)
+}
+
+// ReadLines is ReadFile split into lines.
+func (r FactRequest) ReadLines(path string) ([]string, error) {
+	b, err := r.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n"), nil
+}
+
+// Run executes a command (once per scan) within the request timeout and
+// returns trimmed stdout and stderr.
+func (r FactRequest) Run(name string, args ...string) (string, string, error) {
+	return r.store.Run(r.Context(), name, args...)
+}
+
+// FactCollector produces the observed value of a fact.
+//
+// Name is either an exact fact name ("ssh.banner") or, when it ends in ":",
+// a family prefix ("sysctl:") that handles every "sysctl:<param>" fact.
+// Collect returns the observed value and a short evidence string, or an
+// error when the fact cannot be determined (unreadable file, failed
+// command); the rule is then reported as "error" rather than "fail".
+type FactCollector interface {
+	Name() string
+	Description() string
+	Collect(req FactRequest) (value Value, evidence string, err error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]FactCollector{}
+)
+
+// Register makes a collector available to rules. It is meant to be called
+// from init(); registering an empty or duplicate name panics.
+func Register(c FactCollector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name := c.Name()
+	if name == "" {
+		panic("checks: Register collector with empty name")
+	}
+	if _, dup := registry[name]; dup {
+		panic(fmt.Sprintf("checks: Register called twice for fact %q", name))
+	}
+	registry[name] = c
+}
+
+// LookupCollector finds the collector for fact: an exact name first, then
+// the family prefix up to the first ":".
+func LookupCollector(fact string) (FactCollector, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if c, ok := registry[fact]; ok {
+		return c, true
+	}
+	if family, _, ok := strings.Cut(fact, ":"); ok {
+		if c, ok := registry[family+":"]; ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Collectors returns every registered collector sorted by name.
+func Collectors() []FactCollector {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	out := make([]FactCollector, 0, len(registry))
+	for _, c := range registry {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+// IsFamily reports whether a collector name is a family prefix.
+func IsFamily(name string) bool {
+	return strings.HasSuffix(name, ":")
+}
+
+// NewCollector wraps a function returning a string fact as a FactCollector.
+func NewCollector(name, description string, fn func(FactRequest) (string, string, error)) FactCollector {
+	return NewValueCollector(name, description, func(req FactRequest) (Value, string, error) {
+		v, ev, err := fn(req)
+		return StringValue(v), ev, err
+	})
+}
+
+// NewValueCollector wraps a function returning a typed fact (int, bool,
+// list, map) as a FactCollector.
+func NewValueCollector(name, description string, fn func(FactRequest) (Value, string, error)) FactCollector {
+	return funcCollector{name: name, description: description, fn: fn}
+}
+
+type funcCollector struct {
+	name        string
+	description string
+	fn          func(FactRequest) (Value, string, error)
+}
+
+func (c funcCollector) Name() string        { return c.name }
+func (c funcCollector) Description() string { return c.description }
+func (c funcCollector) Collect(req FactRequest) (Value, string, error) {
+	return c.fn(req)
+}
+
+// fromEvidence adapts the (value, evidence) collectors used throughout this package.
+func fromEvidence(fn func(FactRequest) (string, string)) func(FactRequest) (string, string, error) {
+	return func(req FactRequest) (string, string, error) {
+		v, ev := fn(req)
+		return v, ev, nil
+	}
+}
+
+// fromValue adapts the older (value, error) collectors.
+func fromValue(fn func(FactRequest) (string, error)) func(FactRequest) (string, string, error) {
+	return func(req FactRequest) (string, string, error) {
+		v, err := fn(req)
+		return v, "", err
+	}
+}
+
+// asBool types the "true"/"false" results of the older collectors as
+// bools; anything else (e.g. "unknown") stays a string.
+func asBool(fn func(FactRequest) (string, string, error)) func(FactRequest) (Value, string, error) {
+	return func(req FactRequest) (Value, string, error) {
+		v, ev, err := fn(req)
+		if v == "true" || v == "false" {
+			return BoolValue(v == "true"), ev, err
+		}
+		return StringValue(v), ev, err
+	}
+}
+
+// collectFact resolves a single fact through the registry, once per scan.
+// A collector error comes back as a *CollectionError.
+func collectFact(ctx context.Context, store *FactStore, fact string, timeout time.Duration) (Value, string, error) {
+	return store.Fact(ctx, fact, func() (Value, string, error) {
+		return collectUncached(ctx, store, fact, timeout)
+	})
+}
+
+func collectUncached(ctx context.Context, store *FactStore, fact string, timeout time.Duration) (Value, string, error) {
+	c, ok := LookupCollector(fact)
+	if !ok {
+		// a rule naming an unknown fact is broken, not a misconfiguration
+		err := &CollectionError{Fact: fact, Err: fmt.Errorf("no collector implemented for fact %q", fact)}
+		return Value{}, err.Err.Error(), err
+	}
+
+	req := FactRequest{Fact: fact, Timeout: timeout, Ctx: ctx, store: store}
+	if IsFamily(c.Name()) {
+		req.Param = strings.TrimPrefix(fact, c.Name())
+		if req.Param == "" {
+			err := &CollectionError{Fact: fact, Err: fmt.Errorf("fact family %q needs a parameter (%s<value>)", c.Name(), c.Name())}
+			return Value{}, err.Err.Error(), err
+		}
+	}
+
+	observed, evidence, err := c.Collect(req)
+	if err != nil {
+		if evidence != "" {
+			evidence += "; "
+		}
+		evidence += err.Error()
+		var ce *CollectionError
+		if !errors.As(err, &ce) {
+			err = &CollectionError{Fact: fact, Err: err}
+		}
+	}
+	return observed, evidence, err
+}
@@ -0,0 +1,17 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCollectUnknownFact(t *testing.T) {
+	for _, fact := range []string{"os.id", "sysctl:"} {
+		_, _, err := collectFact(context.Background(), NewFactStore(), fact, 0)
+		var ce *CollectionError
+		if !errors.As(err, &ce) || ce.Fact != fact {
+			t.Errorf("collectFact(%q): err = %v, want a *CollectionError", fact, err)
+		}
+	}
+}
@@ -4,7 +4,7 @@ package checks
 //
 //   - all=true  → return every rule.
 //   - cis=true  → only rules tagged "cis".
-//   - pe=true   → only recon/priv-esc style rules (tagged "recon").
+//   - pe=true   → only recon/priv-esc style rules (see Rule.IsPE).
 func FilterForMode(all, cis, pe bool, rules []Rule) []Rule {
 	var out []Rule
 
@@ -17,7 +17,7 @@ func FilterForMode(all, cis, pe bool, rules []Rule) []Rule {
 			out = append(out, r)
 			continue
 		}
-		if pe && r.HasTag("recon") {
+		if pe && r.IsPE() {
 			out = append(out, r)
 			continue
 		}
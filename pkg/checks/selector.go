@@ -0,0 +1,254 @@
+package checks
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Selector narrows a rule set beyond --all/--cis/--pe. Every set field must
+// match for a rule to be selected; zero fields select everything.
+type Selector struct {
+	Tags        string   // boolean tag expression, e.g. "ssh and not extra"
+	ExcludeTags []string // drop rules carrying any of these tags
+	IDs         []string // ID globs (path.Match syntax), any may match
+	ExcludeIDs  []string // ID globs to drop
+	Categories  []string // case-insensitive
+	MinSeverity string   // Low, Medium, High or Critical
+	CISLevel    int      // only CIS rules up to this level (1 or 2)
+	CISProfile  string   // only CIS rules of this profile: server or workstation
+
+	tags   tagExpr
+	minSev int
+}
+
+// severityRank orders severities for --min-severity.
+var severityRank = map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// Compile validates the selector: tag expression syntax, ID globs and the
+// severity name.
+func (s *Selector) Compile() error {
+	s.tags = nil
+	if strings.TrimSpace(s.Tags) != "" {
+		e, err := parseTagExpr(s.Tags)
+		if err != nil {
+			return fmt.Errorf("--tags: %w", err)
+		}
+		s.tags = e
+	}
+	for _, g := range append(append([]string(nil), s.IDs...), s.ExcludeIDs...) {
+		if _, err := path.Match(g, ""); err != nil {
+			return fmt.Errorf("bad ID pattern %q: %w", g, err)
+		}
+	}
+	s.minSev = 0
+	if s.MinSeverity != "" {
+		rank, ok := severityRank[strings.ToLower(s.MinSeverity)]
+		if !ok {
+			return fmt.Errorf("--min-severity must be Low, Medium, High or Critical, got %q", s.MinSeverity)
+		}
+		s.minSev = rank
+	}
+	if s.CISLevel < 0 || s.CISLevel > 2 {
+		return fmt.Errorf("--level must be 1 or 2, got %d", s.CISLevel)
+	}
+	if s.CISProfile != "" && !ValidCISProfile(s.CISProfile) {
+		return fmt.Errorf("--cis-profile must be server or workstation, got %q", s.CISProfile)
+	}
+	return nil
+}
+
+// validCISProfiles are the CIS applicability profiles rules can declare.
+var validCISProfiles = map[string]bool{"server": true, "workstation": true}
+
+// ValidCISProfile reports whether p is a known CIS profile name.
+func ValidCISProfile(p string) bool { return validCISProfiles[strings.ToLower(p)] }
+
+// Match reports whether r is selected. Compile must have succeeded.
+func (s *Selector) Match(r Rule) bool {
+	if s.tags != nil && !s.tags.eval(r) {
+		return false
+	}
+	for _, t := range s.ExcludeTags {
+		if r.HasTag(t) {
+			return false
+		}
+	}
+	if len(s.IDs) > 0 && !matchAnyGlob(s.IDs, r.ID) {
+		return false
+	}
+	if matchAnyGlob(s.ExcludeIDs, r.ID) {
+		return false
+	}
+	if len(s.Categories) > 0 {
+		found := false
+		for _, c := range s.Categories {
+			if strings.EqualFold(c, r.Category) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if s.minSev > 0 && severityRank[strings.ToLower(r.Severity)] < s.minSev {
+		return false
+	}
+	if (s.CISLevel > 0 || s.CISProfile != "") && !r.InCISProfile(s.CISLevel, s.CISProfile) {
+		return false
+	}
+	return true
+}
+
+// Filter returns the rules Match selects.
+func (s *Selector) Filter(rules []Rule) []Rule {
+	var out []Rule
+	for _, r := range rules {
+		if s.Match(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func matchAnyGlob(globs []string, id string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ── tag expressions ──────────────────────────────────────────────────────────
+//
+//	expr := term { ("or" | ",") term }
+//	term := factor { "and" factor }
+//	factor := "not" factor | "(" expr ")" | tag
+
+type tagExpr interface{ eval(Rule) bool }
+
+type tagLit string
+type tagNot struct{ x tagExpr }
+type tagAnd struct{ l, r tagExpr }
+type tagOr struct{ l, r tagExpr }
+
+func (t tagLit) eval(r Rule) bool { return r.HasTag(string(t)) }
+func (n tagNot) eval(r Rule) bool { return !n.x.eval(r) }
+func (a tagAnd) eval(r Rule) bool { return a.l.eval(r) && a.r.eval(r) }
+func (o tagOr) eval(r Rule) bool  { return o.l.eval(r) || o.r.eval(r) }
+
+// parseTagExpr parses a boolean tag expression such as
+// "ssh and not extra" or "(cis or recon) and not local". A comma is
+// shorthand for "or".
+func parseTagExpr(s string) (tagExpr, error) {
+	p := &tagParser{toks: tokenizeTags(s)}
+	e, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q in %q", p.toks[p.i], s)
+	}
+	return e, nil
+}
+
+func tokenizeTags(s string) []string {
+	var toks []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			toks = append(toks, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, c := range s {
+		switch {
+		case c == '(' || c == ')' || c == ',':
+			flush()
+			toks = append(toks, string(c))
+		case unicode.IsSpace(c):
+			flush()
+		default:
+			cur.WriteRune(c)
+		}
+	}
+	flush()
+	return toks
+}
+
+type tagParser struct {
+	toks []string
+	i    int
+}
+
+func (p *tagParser) peek() string {
+	if p.i < len(p.toks) {
+		return p.toks[p.i]
+	}
+	return ""
+}
+
+func (p *tagParser) expr() (tagExpr, error) {
+	l, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "or" || p.peek() == "," {
+		p.i++
+		r, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		l = tagOr{l, r}
+	}
+	return l, nil
+}
+
+func (p *tagParser) term() (tagExpr, error) {
+	l, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "and" {
+		p.i++
+		r, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		l = tagAnd{l, r}
+	}
+	return l, nil
+}
+
+func (p *tagParser) factor() (tagExpr, error) {
+	tok := p.peek()
+	switch tok {
+	case "":
+		return nil, fmt.Errorf("unexpected end of tag expression")
+	case "not":
+		p.i++
+		x, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return tagNot{x}, nil
+	case "(":
+		p.i++
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.i++
+		return x, nil
+	case ")", ",", "and", "or":
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+	p.i++
+	return tagLit(tok), nil
+}
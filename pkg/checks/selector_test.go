@@ -0,0 +1,103 @@
+package checks
+
+import "testing"
+
+func TestParseTagExpr(t *testing.T) {
+	ssh := Rule{ID: "CIS-5.1.1", Tags: []string{"cis", "ssh"}}
+	sshExtra := Rule{ID: "CIS-5.1.2", Tags: []string{"cis", "ssh", "extra"}}
+	recon := Rule{ID: "RC-1.1", Tags: []string{"recon", "local"}}
+	rules := []Rule{ssh, sshExtra, recon}
+
+	tests := []struct {
+		expr string
+		want []bool // per rule above
+	}{
+		{"ssh", []bool{true, true, false}},
+		{"ssh and not extra", []bool{true, false, false}},
+		{"ssh or recon", []bool{true, true, true}},
+		{"ssh, recon", []bool{true, true, true}},
+		{"not ssh", []bool{false, false, true}},
+		{"not not ssh", []bool{true, true, false}},
+		{"(cis or recon) and not local", []bool{true, true, false}},
+		{"cis and extra or recon", []bool{false, true, true}}, // and binds tighter
+		{"cis and (extra or recon)", []bool{false, true, false}},
+	}
+	for _, tt := range tests {
+		e, err := parseTagExpr(tt.expr)
+		if err != nil {
+			t.Errorf("parseTagExpr(%q): %v", tt.expr, err)
+			continue
+		}
+		for i, r := range rules {
+			if got := e.eval(r); got != tt.want[i] {
+				t.Errorf("%q on %s = %v, want %v", tt.expr, r.ID, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestParseTagExprErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"ssh and",
+		"and ssh",
+		"(ssh or cis",
+		"ssh)",
+		"ssh recon",
+		"not",
+		"ssh,,cis",
+	} {
+		if _, err := parseTagExpr(expr); err == nil {
+			t.Errorf("parseTagExpr(%q): want an error", expr)
+		}
+	}
+}
+
+func TestSelectorMatch(t *testing.T) {
+	l1 := Rule{ID: "CIS-5.1.1", Category: "Auth", Severity: "High", CISLevel: 1, Tags: []string{"cis", "ssh"}}
+	l2srv := Rule{ID: "CIS-5.1.16", Category: "Auth", Severity: "Medium", CISLevel: 2, CISProfiles: []string{"server"}, Tags: []string{"cis", "ssh"}}
+	rc := Rule{ID: "RC-1.1", Category: "Recon", Severity: "High", Tags: []string{"recon"}}
+
+	tests := []struct {
+		name string
+		sel  Selector
+		want []bool // l1, l2srv, rc
+	}{
+		{"empty", Selector{}, []bool{true, true, true}},
+		{"id glob", Selector{IDs: []string{"CIS-5.1.*"}}, []bool{true, true, false}},
+		{"exclude id", Selector{ExcludeIDs: []string{"CIS-5.1.1?"}}, []bool{true, false, true}},
+		{"category case-insensitive", Selector{Categories: []string{"recon"}}, []bool{false, false, true}},
+		{"min severity", Selector{MinSeverity: "high"}, []bool{true, false, true}},
+		{"exclude tag", Selector{ExcludeTags: []string{"ssh"}}, []bool{false, false, true}},
+		{"level 1", Selector{CISLevel: 1}, []bool{true, false, false}},
+		{"level 2 includes level 1", Selector{CISLevel: 2}, []bool{true, true, false}},
+		{"workstation profile", Selector{CISLevel: 2, CISProfile: "workstation"}, []bool{true, false, false}},
+		{"tags and severity", Selector{Tags: "ssh", MinSeverity: "Medium"}, []bool{true, true, false}},
+	}
+	for _, tt := range tests {
+		sel := tt.sel
+		if err := sel.Compile(); err != nil {
+			t.Errorf("%s: Compile: %v", tt.name, err)
+			continue
+		}
+		for i, r := range []Rule{l1, l2srv, rc} {
+			if got := sel.Match(r); got != tt.want[i] {
+				t.Errorf("%s: Match(%s) = %v, want %v", tt.name, r.ID, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestSelectorCompileErrors(t *testing.T) {
+	for name, sel := range map[string]Selector{
+		"tags":        {Tags: "ssh and"},
+		"id glob":     {IDs: []string{"CIS-["}},
+		"severity":    {MinSeverity: "urgent"},
+		"level":       {CISLevel: 3},
+		"cis profile": {CISProfile: "desktop"},
+	} {
+		if err := sel.Compile(); err == nil {
+			t.Errorf("%s: want a Compile error", name)
+		}
+	}
+}
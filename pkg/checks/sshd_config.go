@@ -0,0 +1,381 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SSHDConfigPath is the main sshd configuration file.
+const SSHDConfigPath = "/etc/ssh/sshd_config"
+
+// SSHDSetting is one directive line of the sshd configuration.
+type SSHDSetting struct {
+	Keyword string // as written, e.g. "PermitRootLogin"
+	Value   string // arguments, unquoted and space-joined
+	File    string
+	Line    int
+	Match   string // criteria of the enclosing Match block; "" at global scope
+}
+
+// Pos is "file:line".
+func (s SSHDSetting) Pos() string { return fmt.Sprintf("%s:%d", s.File, s.Line) }
+
+// SSHDConfig models the configuration sshd reads: the main file with every
+// Include expanded in place (globs in lexical order, relative paths under
+// the main file's directory), Match blocks kept apart from the global
+// settings.
+//
+// sshd uses the first value it reads for a keyword and ignores later ones,
+// except for the few keywords that accumulate (AllowUsers, HostKey, Port,
+// ...). A Match block lasts until the next Match line or the end of the
+// file it appears in, so an Include cannot leak one into the file that
+// included it.
+type SSHDConfig struct {
+	Files    []string                 // files read, in order
+	settings map[string][]SSHDSetting // lower-cased keyword -> global lines in order
+	matches  map[string][]SSHDSetting // lower-cased keyword -> lines inside Match blocks
+
+	// Effective holds "sshd -T" output (lower-cased keyword -> value),
+	// nil when it could not be run; EffectiveErr then says why.
+	Effective    map[string]string
+	EffectiveErr string
+}
+
+// sshdAccumulating lists the keywords whose values add up instead of the
+// first one winning.
+var sshdAccumulating = map[string]bool{
+	"allowusers": true, "denyusers": true, "allowgroups": true, "denygroups": true,
+	"hostkey": true, "hostcertificate": true, "port": true, "listenaddress": true,
+	"acceptenv": true, "subsystem": true,
+}
+
+// sshdDefaults are OpenSSH's compiled-in values for the keywords rules
+// check, used when neither the files nor "sshd -T" give one.
+var sshdDefaults = map[string]string{
+	"allowtcpforwarding": "yes", "clientalivecountmax": "3", "clientaliveinterval": "0",
+	"disableforwarding": "no", "hostbasedauthentication": "no", "ignorerhosts": "yes",
+	"logingracetime": "120", "loglevel": "INFO", "maxauthtries": "6", "maxsessions": "10",
+	"maxstartups": "10:30:100", "permitemptypasswords": "no", "permitrootlogin": "prohibit-password",
+	"permituserenvironment": "no", "usepam": "no", "x11forwarding": "no",
+}
+
+const sshdMaxIncludeDepth = 16
+
+// ParseSSHDConfig reads path and everything it includes through read,
+// expanding Include globs with the listings of readDir.
+func ParseSSHDConfig(path string, read func(string) ([]byte, error), readDir func(string) ([]fs.DirEntry, error)) (*SSHDConfig, error) {
+	c := &SSHDConfig{
+		settings: map[string][]SSHDSetting{},
+		matches:  map[string][]SSHDSetting{},
+	}
+	p := sshdParser{c: c, read: read, readDir: readDir}
+	if err := p.parseFile(path, filepath.Dir(path), "", 0); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// sshdParser carries the model being built and the file access it reads
+// through.
+type sshdParser struct {
+	c       *SSHDConfig
+	read    func(string) ([]byte, error)
+	readDir func(string) ([]fs.DirEntry, error)
+}
+
+func (p sshdParser) parseFile(path, baseDir, match string, depth int) error {
+	c := p.c
+	if depth > sshdMaxIncludeDepth {
+		return fmt.Errorf("%s: Include nested more than %d levels", path, sshdMaxIncludeDepth)
+	}
+	data, err := p.read(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	c.Files = append(c.Files, path)
+
+	for i, raw := range strings.Split(string(data), "\n") {
+		keyword, args := splitSSHDLine(raw)
+		if keyword == "" {
+			continue
+		}
+		lower := strings.ToLower(keyword)
+		switch lower {
+		case "match":
+			match = strings.Join(args, " ")
+			continue
+		case "include":
+			for _, pattern := range args {
+				if !filepath.IsAbs(pattern) {
+					pattern = filepath.Join(baseDir, pattern)
+				}
+				files, err := globDir(pattern, p.readDir)
+				if err != nil {
+					return fmt.Errorf("%s:%d: bad Include pattern %q: %w", path, i+1, pattern, err)
+				}
+				for _, f := range files {
+					if err := p.parseFile(f, baseDir, match, depth+1); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+		s := SSHDSetting{Keyword: keyword, Value: strings.Join(args, " "), File: path, Line: i + 1, Match: match}
+		if match != "" {
+			c.matches[lower] = append(c.matches[lower], s)
+		} else {
+			c.settings[lower] = append(c.settings[lower], s)
+		}
+	}
+	return nil
+}
+
+// globDir is filepath.Glob over the listings of readDir: the matches of
+// pattern in lexical order, nil when none exist. Directories that cannot be
+// listed match nothing.
+func globDir(pattern string, readDir func(string) ([]fs.DirEntry, error)) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	dir, base := filepath.Split(pattern)
+	dir = filepath.Clean(dir)
+	dirs := []string{dir}
+	if strings.ContainsAny(dir, `*?[\`) {
+		var err error
+		if dirs, err = globDir(dir, readDir); err != nil {
+			return nil, err
+		}
+	}
+	var out []string
+	for _, d := range dirs {
+		entries, err := readDir(d)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if ok, _ := filepath.Match(base, e.Name()); ok {
+				out = append(out, filepath.Join(d, e.Name()))
+			}
+		}
+	}
+	return out, nil
+}
+
+// splitSSHDLine splits a config line into keyword and arguments. It accepts
+// "Keyword value" and "Keyword=value", strips double quotes and stops at a
+// comment.
+func splitSSHDLine(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil
+	}
+	i := strings.IndexAny(line, " \t=")
+	if i < 0 {
+		return line, nil
+	}
+	keyword := line[:i]
+	rest := strings.TrimLeft(line[i:], " \t")
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "="), " \t")
+
+	var args []string
+	for len(rest) > 0 {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" || rest[0] == '#' {
+			break
+		}
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				args = append(args, rest[1:])
+				break
+			}
+			args = append(args, rest[1:end+1])
+			rest = rest[end+2:]
+			continue
+		}
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		args = append(args, rest[:end])
+		rest = rest[end:]
+	}
+	return keyword, args
+}
+
+// Lookup returns the global value sshd uses for keyword: the first line,
+// or all of them joined for accumulating keywords.
+func (c *SSHDConfig) Lookup(keyword string) (SSHDSetting, bool) {
+	lines := c.settings[strings.ToLower(keyword)]
+	if len(lines) == 0 {
+		return SSHDSetting{}, false
+	}
+	s := lines[0]
+	if sshdAccumulating[strings.ToLower(keyword)] {
+		values := make([]string, len(lines))
+		for i, l := range lines {
+			values[i] = l.Value
+		}
+		s.Value = strings.Join(values, " ")
+	}
+	return s, true
+}
+
+// Ignored returns the global lines for keyword that sshd skips because an
+// earlier line already set it.
+func (c *SSHDConfig) Ignored(keyword string) []SSHDSetting {
+	lines := c.settings[strings.ToLower(keyword)]
+	if len(lines) < 2 || sshdAccumulating[strings.ToLower(keyword)] {
+		return nil
+	}
+	return lines[1:]
+}
+
+// MatchSettings returns the lines setting keyword inside Match blocks; they
+// override the global value for the connections they match.
+func (c *SSHDConfig) MatchSettings(keyword string) []SSHDSetting {
+	return c.matches[strings.ToLower(keyword)]
+}
+
+// Value resolves keyword for facts. "sshd -T" is authoritative when it ran
+// (it includes compiled-in defaults); otherwise the model's global value is
+// used, then the default from sshdDefaults, and "" means unset. The evidence names the file and line that set
+// the value, lines sshd ignores, Match overrides and any disagreement with
+// "sshd -T".
+func (c *SSHDConfig) Value(keyword string) (string, string) {
+	s, set := c.Lookup(keyword)
+	eff, effOK := c.Effective[strings.ToLower(keyword)]
+
+	var ev []string
+	value := ""
+	switch {
+	case set:
+		value = s.Value
+		ev = append(ev, fmt.Sprintf("%s %s (%s)", s.Keyword, s.Value, s.Pos()))
+	case effOK:
+		ev = append(ev, fmt.Sprintf("%s not set in the %d config file(s); sshd default", keyword, len(c.Files)))
+	default:
+		def, ok := sshdDefaults[strings.ToLower(keyword)]
+		if ok {
+			value = def
+			ev = append(ev, fmt.Sprintf("%s not set explicitly; sshd default %s", keyword, def))
+		} else {
+			ev = append(ev, fmt.Sprintf("%s not set explicitly; relying on sshd default", keyword))
+		}
+	}
+	for _, ig := range c.Ignored(keyword) {
+		ev = append(ev, fmt.Sprintf("ignored %s %s (%s; first value wins)", ig.Keyword, ig.Value, ig.Pos()))
+	}
+	for _, m := range c.MatchSettings(keyword) {
+		ev = append(ev, fmt.Sprintf("Match %s overrides: %s %s (%s)", m.Match, m.Keyword, m.Value, m.Pos()))
+	}
+	if c.Effective == nil && c.EffectiveErr != "" {
+		ev = append(ev, c.EffectiveErr)
+	}
+	if effOK {
+		if eff == "without-password" {
+			eff = "prohibit-password" // old name sshd -T still prints
+		}
+		if set && !sshdSameValue(s.Value, eff) {
+			ev = append(ev, fmt.Sprintf("sshd -T reports %s %s, not what the config files give", strings.ToLower(keyword), eff))
+		} else {
+			ev = append(ev, fmt.Sprintf("sshd -T: %s %s", strings.ToLower(keyword), eff))
+		}
+		value = eff
+	}
+	return value, strings.Join(ev, "; ")
+}
+
+// parseSSHDEffective parses "sshd -T" output.
+func parseSSHDEffective(out string) map[string]string {
+	eff := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		k, v, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if k == "" {
+			continue
+		}
+		if prev, ok := eff[k]; ok && sshdAccumulating[k] {
+			v = prev + " " + v
+		}
+		eff[k] = v
+	}
+	return eff
+}
+
+// sshdSameValue compares a config file value with "sshd -T" output, which
+// lower-cases keywords and many values, spells durations in seconds and
+// still prints prohibit-password under its old name.
+func sshdSameValue(configured, effective string) bool {
+	norm := func(s string) string {
+		s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+		if s == "without-password" {
+			s = "prohibit-password"
+		}
+		if n, ok := sshdSeconds(s); ok {
+			s = strconv.Itoa(n)
+		}
+		return s
+	}
+	if configured != "" && strings.ContainsRune("+-^", rune(configured[0])) {
+		return true // algorithm list edits; the result is what -T prints
+	}
+	return norm(configured) == norm(effective)
+}
+
+// sshdSeconds parses sshd time formats ("120", "2m", "1h30m").
+func sshdSeconds(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	total, n, digits := 0, 0, false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch >= '0' && ch <= '9':
+			n = n*10 + int(ch-'0')
+			digits = true
+		case units[ch] > 0 && digits:
+			total += n * units[ch]
+			n, digits = 0, false
+		default:
+			return 0, false
+		}
+	}
+	return total + n, true
+}
+
+// sshdConfig returns the scan's sshd model, parsed once, with "sshd -T"
+// output attached when it can be run. A timeout or cancellation while
+// sshd -T runs fails the lookup instead of caching a model without it.
+func sshdConfig(req FactRequest) (*SSHDConfig, error) {
+	v, err := req.store.do(req.Context(), kindParsed, SSHDConfigPath, func() (any, error) {
+		c, err := ParseSSHDConfig(SSHDConfigPath, req.ReadFile, req.ReadDir)
+		if err != nil {
+			return nil, err
+		}
+		// needs root and the sshd binary; without it the model stands alone
+		out, errOut, err := req.Run("sshd", "-T")
+		switch {
+		case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled):
+			return nil, fmt.Errorf("sshd -T: %w", err)
+		case err != nil:
+			c.EffectiveErr = fmt.Sprintf("sshd -T failed: %v (stderr: %s); config files only", err, errOut)
+		case out == "":
+			c.EffectiveErr = "sshd -T printed nothing; config files only"
+		default:
+			c.Effective = parseSSHDEffective(out)
+		}
+		return c, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*SSHDConfig), nil
+}
@@ -0,0 +1,129 @@
+package checks
+
+import (
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// mapFSAccess serves absolute paths from m, the way the fact store serves
+// the live filesystem.
+func mapFSAccess(m fstest.MapFS) (func(string) ([]byte, error), func(string) ([]fs.DirEntry, error)) {
+	rel := func(p string) string {
+		if p = strings.TrimPrefix(p, "/"); p == "" {
+			return "."
+		}
+		return p
+	}
+	return func(p string) ([]byte, error) { return fs.ReadFile(m, rel(p)) },
+		func(p string) ([]fs.DirEntry, error) { return fs.ReadDir(m, rel(p)) }
+}
+
+func TestSplitSSHDLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		keyword string
+		args    []string
+	}{
+		{"", "", nil},
+		{"  # comment", "", nil},
+		{"PermitRootLogin no", "PermitRootLogin", []string{"no"}},
+		{"PermitRootLogin=no", "PermitRootLogin", []string{"no"}},
+		{"MaxAuthTries = 4", "MaxAuthTries", []string{"4"}},
+		{"\tAllowUsers alice bob # admins", "AllowUsers", []string{"alice", "bob"}},
+		{`Banner "/etc/issue net"`, "Banner", []string{"/etc/issue net"}},
+		{"UsePAM", "UsePAM", nil},
+	}
+	for _, tt := range tests {
+		keyword, args := splitSSHDLine(tt.line)
+		if keyword != tt.keyword || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("splitSSHDLine(%q) = %q %q, want %q %q", tt.line, keyword, args, tt.keyword, tt.args)
+		}
+	}
+}
+
+func TestParseSSHDConfig(t *testing.T) {
+	read, readDir := mapFSAccess(fstest.MapFS{
+		"etc/ssh/sshd_config": {Data: []byte(
+			"Include sshd_config.d/*.conf\n" +
+				"PermitRootLogin yes\n" +
+				"AllowUsers alice\n" +
+				"Match User backup\n" +
+				"  PermitRootLogin forced-commands-only\n")},
+		"etc/ssh/sshd_config.d/10-first.conf": {Data: []byte("PermitRootLogin no\nAllowUsers bob\nMatch Group sftp\n  X11Forwarding yes\n")},
+		"etc/ssh/sshd_config.d/20-later.conf": {Data: []byte("X11Forwarding no\nPermitRootLogin prohibit-password\n")},
+		"etc/ssh/sshd_config.d/notes.txt":     {Data: []byte("PermitRootLogin yes\n")},
+	})
+	c, err := ParseSSHDConfig(SSHDConfigPath, read, readDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFiles := []string{SSHDConfigPath, "/etc/ssh/sshd_config.d/10-first.conf", "/etc/ssh/sshd_config.d/20-later.conf"}
+	if !reflect.DeepEqual(c.Files, wantFiles) {
+		t.Errorf("Files = %q, want %q", c.Files, wantFiles)
+	}
+
+	// first value wins, from the earliest include
+	s, ok := c.Lookup("permitrootlogin")
+	if !ok || s.Value != "no" || s.Pos() != "/etc/ssh/sshd_config.d/10-first.conf:1" {
+		t.Errorf("PermitRootLogin = %q at %s, want no at 10-first.conf:1", s.Value, s.Pos())
+	}
+	if n := len(c.Ignored("PermitRootLogin")); n != 2 {
+		t.Errorf("Ignored(PermitRootLogin) = %d lines, want 2", n)
+	}
+	// accumulating keywords add up
+	if s, _ := c.Lookup("AllowUsers"); s.Value != "bob alice" {
+		t.Errorf("AllowUsers = %q, want %q", s.Value, "bob alice")
+	}
+	// a Match block ends with the included file
+	if s, _ := c.Lookup("X11Forwarding"); s.Value != "no" {
+		t.Errorf("X11Forwarding = %q, want no (the Match in 10-first.conf must not leak)", s.Value)
+	}
+	if m := c.MatchSettings("PermitRootLogin"); len(m) != 1 || m[0].Match != "User backup" {
+		t.Errorf("MatchSettings(PermitRootLogin) = %+v, want one under User backup", m)
+	}
+}
+
+func TestSSHDConfigValue(t *testing.T) {
+	read, readDir := mapFSAccess(fstest.MapFS{
+		"etc/ssh/sshd_config": {Data: []byte("LoginGraceTime 1m\n")},
+	})
+	c, err := ParseSSHDConfig(SSHDConfigPath, read, readDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.EffectiveErr = "sshd -T failed: exit status 255 (stderr: ); config files only"
+	if v, ev := c.Value("MaxAuthTries"); v != "6" || !strings.Contains(ev, "sshd -T failed") {
+		t.Errorf("Value(MaxAuthTries) = %q, %q; want the default 6 and the sshd -T failure", v, ev)
+	}
+
+	c.Effective = parseSSHDEffective("logingracetime 60\npermitrootlogin without-password\nmaxauthtries 4\n")
+	if v, ev := c.Value("LoginGraceTime"); v != "60" || strings.Contains(ev, "not what the config files give") {
+		t.Errorf("Value(LoginGraceTime) = %q, %q; want 60 agreeing with 1m", v, ev)
+	}
+	if v, _ := c.Value("PermitRootLogin"); v != "prohibit-password" {
+		t.Errorf("Value(PermitRootLogin) = %q, want prohibit-password", v)
+	}
+}
+
+func TestGlobDir(t *testing.T) {
+	_, readDir := mapFSAccess(fstest.MapFS{
+		"etc/ssh/a.d/2.conf": {},
+		"etc/ssh/a.d/1.conf": {},
+		"etc/ssh/b.d/3.conf": {},
+		"etc/ssh/b.d/x.txt":  {},
+	})
+	got, err := globDir("/etc/ssh/*.d/*.conf", readDir)
+	want := []string{"/etc/ssh/a.d/1.conf", "/etc/ssh/a.d/2.conf", "/etc/ssh/b.d/3.conf"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("globDir = %q, %v; want %q", got, err, want)
+	}
+	if got, _ := globDir("/etc/ssh/none.d/*.conf", readDir); got != nil {
+		t.Errorf("globDir on a missing directory = %q, want nil", got)
+	}
+	if _, err := globDir("/etc/ssh/[.conf", readDir); err == nil {
+		t.Error("globDir with a bad pattern: want an error")
+	}
+}
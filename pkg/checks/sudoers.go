@@ -0,0 +1,792 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SudoersPath is the main sudoers file.
+const SudoersPath = "/etc/sudoers"
+
+// SudoersDefault is one setting of a Defaults line.
+type SudoersDefault struct {
+	Scope   byte     // 0 for global Defaults, else ':' user, '@' host, '>' runas or '!' command
+	Binding []string // who the scope applies to, aliases expanded
+	Name    string
+	Op      string // "=", "+=", "-=" or "" for a flag
+	Value   string
+	Negated bool // "!name"
+	File    string
+	Line    int
+}
+
+// SudoersGrant is one command of a user specification, with the users,
+// hosts, run-as lists and tags that apply to it. Aliases are expanded, and a
+// leading "!" marks an excluded item. No RunAs spec means the runas_default
+// user (root).
+type SudoersGrant struct {
+	Users       []string
+	Hosts       []string
+	RunAsSet    bool
+	RunAsUsers  []string
+	RunAsGroups []string
+	Tags        []string // NOPASSWD, SETENV, NOEXEC, ...
+	Command     string   // "ALL" or a path with optional arguments
+	Negated     bool
+	File        string
+	Line        int
+}
+
+// Pos is "file:line".
+func (g SudoersGrant) Pos() string { return fmt.Sprintf("%s:%d", g.File, g.Line) }
+
+// String renders the grant as a one-command sudoers line.
+func (g SudoersGrant) String() string {
+	var b strings.Builder
+	b.WriteString(strings.Join(g.Users, ", "))
+	b.WriteString(" " + strings.Join(g.Hosts, ", ") + " =")
+	if g.RunAsSet {
+		b.WriteString(" (" + strings.Join(g.RunAsUsers, ", "))
+		if len(g.RunAsGroups) > 0 {
+			b.WriteString(":" + strings.Join(g.RunAsGroups, ", "))
+		}
+		b.WriteString(")")
+	}
+	for _, t := range g.Tags {
+		b.WriteString(" " + t + ":")
+	}
+	b.WriteString(" ")
+	if g.Negated {
+		b.WriteString("!")
+	}
+	b.WriteString(g.Command)
+	return b.String()
+}
+
+// SudoersConfig models the policy sudo reads: the main file with every
+// @include and @includedir (or the older #include forms) expanded in place,
+// continuation lines joined, and User_Alias, Runas_Alias, Host_Alias and
+// Cmnd_Alias definitions expanded into the grants and Defaults that use
+// them.
+//
+// As in sudo, when several grants match a request the last one wins, so a
+// later "!command" takes back what an earlier line gave.
+type SudoersConfig struct {
+	Files    []string // files read, in order
+	Defaults []SudoersDefault
+	Grants   []SudoersGrant
+
+	// Host is this machine's name as host lists and %h match it, looked
+	// up once when the config is parsed.
+	Host string
+
+	aliases map[string]map[string][]string // alias kind -> name -> members
+}
+
+const sudoersMaxIncludeDepth = 128 // sudo's own limit
+
+var (
+	sudoersAliasRE  = regexp.MustCompile(`^(User_Alias|Runas_Alias|Host_Alias|Cmnd_Alias|Cmd_Alias)\s+`)
+	sudoersIncRE    = regexp.MustCompile(`^[@#](include|includedir)\s+(.+)$`)
+	sudoersTagRE    = regexp.MustCompile(`^(NO)?(PASSWD|EXEC|SETENV|LOG_INPUT|LOG_OUTPUT|MAIL|FOLLOW|INTERCEPT):`)
+	sudoersOptionRE = regexp.MustCompile(`^(ROLE|TYPE|CWD|CHROOT|TIMEOUT|NOTBEFORE|NOTAFTER|APPARMOR_PROFILE|PRIVS|LIMITPRIVS)=`)
+	sudoersDigestRE = regexp.MustCompile(`^sha(224|256|384|512):\S+\s*`)
+)
+
+// ParseSudoersConfig reads path and everything it includes through read;
+// include directories are listed with readDir.
+func ParseSudoersConfig(path string, read func(string) ([]byte, error), readDir func(string) ([]fs.DirEntry, error)) (*SudoersConfig, error) {
+	c := &SudoersConfig{aliases: map[string]map[string][]string{}}
+	c.Host, _ = os.Hostname()
+	if err := c.parseFile(path, read, readDir, 0); err != nil {
+		return nil, err
+	}
+	c.resolve()
+	return c, nil
+}
+
+func (c *SudoersConfig) parseFile(path string, read func(string) ([]byte, error), readDir func(string) ([]fs.DirEntry, error), depth int) error {
+	if depth > sudoersMaxIncludeDepth {
+		return fmt.Errorf("%s: includes nested more than %d levels", path, sudoersMaxIncludeDepth)
+	}
+	data, err := read(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	c.Files = append(c.Files, path)
+
+	for _, ll := range sudoersLogicalLines(string(data)) {
+		line := strings.TrimSpace(ll.text)
+		if m := sudoersIncRE.FindStringSubmatch(line); m != nil {
+			target := sudoersIncludePath(strings.TrimSpace(m[2]), path, c.Host)
+			if m[1] == "include" {
+				if err := c.parseFile(target, read, readDir, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			ents, err := readDir(target)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("%s:%d: read %s: %w", path, ll.line, target, err)
+			}
+			var names []string
+			for _, e := range ents {
+				// sudo skips editor backups and names with a dot (rpm leftovers)
+				if !e.IsDir() && !strings.HasSuffix(e.Name(), "~") && !strings.Contains(e.Name(), ".") {
+					names = append(names, e.Name())
+				}
+			}
+			sort.Strings(names)
+			for _, n := range names {
+				if err := c.parseFile(filepath.Join(target, n), read, readDir, depth+1); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		line = strings.TrimSpace(sudoersStripComment(line))
+		if line == "" {
+			continue
+		}
+		var perr error
+		switch {
+		case strings.HasPrefix(line, "Defaults") && (len(line) == 8 || strings.ContainsRune(" \t:@!>", rune(line[8]))):
+			perr = c.parseDefaults(line[8:], path, ll.line)
+		case sudoersAliasRE.MatchString(line):
+			perr = c.parseAlias(line)
+		default:
+			perr = c.parseUserSpec(line, path, ll.line)
+		}
+		if perr != nil {
+			return fmt.Errorf("%s:%d: %w", path, ll.line, perr)
+		}
+	}
+	return nil
+}
+
+type sudoersLine struct {
+	text string
+	line int
+}
+
+// sudoersLogicalLines joins lines ending in a backslash with the next one.
+func sudoersLogicalLines(data string) []sudoersLine {
+	var out []sudoersLine
+	var cur strings.Builder
+	start := 0
+	for i, raw := range strings.Split(data, "\n") {
+		if cur.Len() == 0 {
+			start = i + 1
+		}
+		trimmed := strings.TrimRight(raw, " \t\r")
+		if n := len(trimmed) - len(strings.TrimRight(trimmed, `\`)); n%2 == 1 {
+			cur.WriteString(trimmed[:len(trimmed)-1] + " ")
+			continue
+		}
+		cur.WriteString(raw)
+		out = append(out, sudoersLine{cur.String(), start})
+		cur.Reset()
+	}
+	if cur.Len() > 0 {
+		out = append(out, sudoersLine{cur.String(), start})
+	}
+	return out
+}
+
+// sudoersStripComment cuts a line at a '#' that starts a comment: not
+// escaped, not quoted, and not a "#uid" or "%#gid" reference.
+func sudoersStripComment(line string) string {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case ch == '\\':
+			i++
+		case ch == '"':
+			quoted = !quoted
+		case ch == '#' && !quoted:
+			if i+1 < len(line) && line[i+1] >= '0' && line[i+1] <= '9' {
+				continue
+			}
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// sudoersIncludePath resolves an include target: quotes stripped, %h
+// replaced by the short host name, relative paths under the including
+// file's directory.
+func sudoersIncludePath(p, from, host string) string {
+	p = strings.Trim(p, `"`)
+	if strings.Contains(p, "%h") {
+		short, _, _ := strings.Cut(host, ".")
+		p = strings.ReplaceAll(p, "%h", short)
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(filepath.Dir(from), p)
+	}
+	return p
+}
+
+// sudoersSplit splits s at unescaped, unquoted occurrences of sep and trims
+// the parts; empty parts are dropped.
+func sudoersSplit(s string, sep byte) []string {
+	var out []string
+	quoted, start := false, 0
+	add := func(part string) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				add(s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add(s[start:])
+	return out
+}
+
+// sudoersUnquote removes surrounding quotes and backslash escapes.
+func sudoersUnquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// sudoersList splits a comma-separated list and normalises "! item" to
+// "!item".
+func sudoersList(s string) []string {
+	var out []string
+	for _, item := range sudoersSplit(s, ',') {
+		neg := false
+		for strings.HasPrefix(item, "!") {
+			neg = !neg
+			item = strings.TrimSpace(item[1:])
+		}
+		item = sudoersUnquote(item)
+		if neg {
+			item = "!" + item
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func (c *SudoersConfig) parseDefaults(rest, file string, line int) error {
+	d := SudoersDefault{File: file, Line: line}
+	if rest != "" && strings.ContainsRune(":@!>", rune(rest[0])) {
+		d.Scope = rest[0]
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			return fmt.Errorf("Defaults%s without settings", rest)
+		}
+		d.Binding = sudoersList(rest[1:end])
+		rest = rest[end:]
+	}
+	params := sudoersSplit(rest, ',')
+	if len(params) == 0 {
+		return fmt.Errorf("Defaults without settings")
+	}
+	for _, p := range params {
+		s := d
+		if strings.HasPrefix(p, "!") {
+			s.Negated = true
+			p = strings.TrimSpace(p[1:])
+		}
+		if i := strings.IndexByte(p, '='); i > 0 {
+			s.Name, s.Op, s.Value = strings.TrimSpace(p[:i]), "=", sudoersUnquote(p[i+1:])
+			if strings.HasSuffix(s.Name, "+") || strings.HasSuffix(s.Name, "-") {
+				s.Op = s.Name[len(s.Name)-1:] + "="
+				s.Name = strings.TrimSpace(s.Name[:len(s.Name)-1])
+			}
+		} else {
+			s.Name = p
+		}
+		c.Defaults = append(c.Defaults, s)
+	}
+	return nil
+}
+
+func (c *SudoersConfig) parseAlias(line string) error {
+	m := sudoersAliasRE.FindStringSubmatch(line)
+	kind, rest := m[1], line[len(m[0]):]
+	if kind == "Cmd_Alias" {
+		kind = "Cmnd_Alias"
+	}
+	if c.aliases[kind] == nil {
+		c.aliases[kind] = map[string][]string{}
+	}
+	for _, def := range sudoersSplit(rest, ':') {
+		name, members, ok := strings.Cut(def, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return fmt.Errorf("%s: expected NAME = members, got %q", kind, def)
+		}
+		c.aliases[kind][name] = sudoersList(members)
+	}
+	return nil
+}
+
+// parseUserSpec parses "User_List Host_List = Cmnd_Spec_List", with more
+// ": Host_List = Cmnd_Spec_List" sections optionally following.
+func (c *SudoersConfig) parseUserSpec(line, file string, lineNo int) error {
+	users, rest := sudoersLeadingList(line)
+	if len(users) == 0 || rest == "" {
+		return fmt.Errorf("cannot parse %q", line)
+	}
+	for rest != "" {
+		hostPart, cmnds, ok := strings.Cut(rest, "=")
+		if !ok {
+			return fmt.Errorf("missing '=' in %q", line)
+		}
+		hosts := sudoersList(hostPart)
+		if len(hosts) == 0 {
+			return fmt.Errorf("no host list in %q", line)
+		}
+		var err error
+		if rest, err = c.parseCmndSpecs(cmnds, SudoersGrant{Users: users, Hosts: hosts, File: file, Line: lineNo}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sudoersLeadingList reads the comma-separated list at the start of s, which
+// ends at the first whitespace not followed by a comma.
+func sudoersLeadingList(s string) ([]string, string) {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '\\':
+			i++
+		case ch == '"':
+			quoted = !quoted
+		case (ch == ' ' || ch == '\t') && !quoted:
+			before := strings.TrimRight(s[:i], " \t")
+			after := strings.TrimLeft(s[i:], " \t")
+			if strings.HasSuffix(before, ",") || strings.HasPrefix(after, ",") || strings.HasSuffix(before, "!") {
+				continue
+			}
+			return sudoersList(s[:i]), strings.TrimSpace(s[i:])
+		}
+	}
+	return sudoersList(s), ""
+}
+
+// parseCmndSpecs parses one Cmnd_Spec_List into grants based on tmpl. A
+// RunAs spec and tags carry over to the commands after them. It returns
+// what follows a ':' that starts another Host_List section.
+func (c *SudoersConfig) parseCmndSpecs(s string, tmpl SudoersGrant) (string, error) {
+	g := tmpl
+	for {
+		s = strings.TrimSpace(s)
+		if strings.HasPrefix(s, "(") {
+			end := strings.IndexByte(s, ')')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated RunAs spec in %q", s)
+			}
+			users, groups, _ := strings.Cut(s[1:end], ":")
+			g.RunAsSet, g.RunAsUsers, g.RunAsGroups = true, sudoersList(users), sudoersList(groups)
+			s = strings.TrimSpace(s[end+1:])
+		}
+		for {
+			if m := sudoersOptionRE.FindString(s); m != "" {
+				s = s[len(m):]
+				if end := strings.IndexAny(s, " \t"); end >= 0 {
+					s = strings.TrimSpace(s[end:])
+				} else {
+					s = ""
+				}
+				continue
+			}
+			if m := sudoersTagRE.FindStringSubmatch(s); m != nil {
+				g.Tags = sudoersSetTag(g.Tags, m[1]+m[2])
+				s = strings.TrimSpace(s[len(m[0]):])
+				continue
+			}
+			if m := sudoersDigestRE.FindString(s); m != "" {
+				s = s[len(m):]
+				continue
+			}
+			break
+		}
+
+		// the command runs to the next unescaped ',' or ':'
+		end := len(s)
+		for i := 0; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == ',' || s[i] == ':' {
+				end = i
+				break
+			}
+		}
+		cmd := strings.TrimSpace(s[:end])
+		neg := false
+		for strings.HasPrefix(cmd, "!") {
+			neg = !neg
+			cmd = strings.TrimSpace(cmd[1:])
+		}
+		if cmd == "" {
+			return "", fmt.Errorf("missing command in %q", s)
+		}
+		g.Command, g.Negated = sudoersUnquote(cmd), neg
+		g.Tags = append([]string(nil), g.Tags...)
+		c.Grants = append(c.Grants, g)
+
+		if end == len(s) {
+			return "", nil
+		}
+		if s[end] == ':' {
+			return strings.TrimSpace(s[end+1:]), nil
+		}
+		s = s[end+1:]
+	}
+}
+
+// sudoersSetTag adds tag, replacing its opposite (NOPASSWD and PASSWD, ...).
+func sudoersSetTag(tags []string, tag string) []string {
+	opposite := "NO" + tag
+	if t, ok := strings.CutPrefix(tag, "NO"); ok {
+		opposite = t
+	}
+	out := []string{}
+	for _, t := range tags {
+		if t != tag && t != opposite {
+			out = append(out, t)
+		}
+	}
+	return append(out, tag)
+}
+
+// resolve expands aliases in grants and Defaults bindings.
+func (c *SudoersConfig) resolve() {
+	var grants []SudoersGrant
+	for _, g := range c.Grants {
+		g.Users = c.expand("User_Alias", g.Users)
+		g.Hosts = c.expand("Host_Alias", g.Hosts)
+		g.RunAsUsers = c.expand("Runas_Alias", g.RunAsUsers)
+		g.RunAsGroups = c.expand("Runas_Alias", g.RunAsGroups)
+		cmd := g.Command
+		if g.Negated {
+			cmd = "!" + cmd
+		}
+		for _, e := range c.expand("Cmnd_Alias", []string{cmd}) {
+			g.Command, g.Negated = strings.TrimPrefix(e, "!"), strings.HasPrefix(e, "!")
+			grants = append(grants, g)
+		}
+	}
+	c.Grants = grants
+
+	kinds := map[byte]string{':': "User_Alias", '@': "Host_Alias", '>': "Runas_Alias", '!': "Cmnd_Alias"}
+	for i, d := range c.Defaults {
+		if kind, ok := kinds[d.Scope]; ok {
+			c.Defaults[i].Binding = c.expand(kind, d.Binding)
+		}
+	}
+}
+
+func (c *SudoersConfig) expand(kind string, items []string) []string {
+	return c.expandSeen(kind, items, map[string]bool{})
+}
+
+func (c *SudoersConfig) expandSeen(kind string, items []string, seen map[string]bool) []string {
+	var out []string
+	for _, item := range items {
+		name, neg := strings.CutPrefix(item, "!")
+		members, ok := c.aliases[kind][name]
+		if !ok || seen[name] {
+			out = append(out, item)
+			continue
+		}
+		seen[name] = true
+		for _, m := range c.expandSeen(kind, members, seen) {
+			if neg {
+				if s, ok := strings.CutPrefix(m, "!"); ok {
+					m = s
+				} else {
+					m = "!" + m
+				}
+			}
+			out = append(out, m)
+		}
+		delete(seen, name)
+	}
+	return out
+}
+
+// SudoUser is an account as sudoers matches it.
+type SudoUser struct {
+	Name   string
+	UID    int
+	GIDs   []int    // primary and supplementary
+	Groups []string // names of GIDs
+}
+
+var sudoRootUser = SudoUser{Name: "root", UID: 0, GIDs: []int{0}, Groups: []string{"root"}}
+
+// matches reports whether a user list item (without "!") names u.
+func (u SudoUser) matches(item string) bool {
+	switch {
+	case item == "ALL":
+		return true
+	case strings.HasPrefix(item, "%#"):
+		gid, err := strconv.Atoi(item[2:])
+		for _, g := range u.GIDs {
+			if err == nil && g == gid {
+				return true
+			}
+		}
+		return false
+	case strings.HasPrefix(item, "%:"), strings.HasPrefix(item, "+"):
+		return false // non-Unix groups and netgroups need the live lookups
+	case strings.HasPrefix(item, "%"):
+		for _, g := range u.Groups {
+			if g == item[1:] {
+				return true
+			}
+		}
+		return false
+	case strings.HasPrefix(item, "#"):
+		uid, err := strconv.Atoi(item[1:])
+		return err == nil && uid == u.UID
+	}
+	return item == u.Name
+}
+
+// sudoersListMatch applies a list the way sudo does: the last item that
+// matches decides, and a "!" item excludes.
+func sudoersListMatch(items []string, match func(string) bool) bool {
+	for i := len(items) - 1; i >= 0; i-- {
+		item, neg := strings.CutPrefix(items[i], "!")
+		if match(item) {
+			return !neg
+		}
+	}
+	return false
+}
+
+// hostMatch matches host list items against c.Host and its short form.
+// Addresses, networks and netgroups are not resolved and do not match.
+func (c *SudoersConfig) hostMatch(item string) bool {
+	if item == "ALL" {
+		return true
+	}
+	short, _, _ := strings.Cut(c.Host, ".")
+	return strings.EqualFold(item, c.Host) || strings.EqualFold(item, short)
+}
+
+// sudoersCmndMatch reports whether a Defaults!command item covers cmd, a
+// grant's command: the same path (shell globs allowed) with no arguments
+// or the same arguments. An item other than ALL does not cover a grant of
+// ALL, which also runs commands the item does not name.
+func sudoersCmndMatch(item, cmd string) bool {
+	if item == "ALL" {
+		return true
+	}
+	if cmd == "ALL" {
+		return false
+	}
+	itemPath, itemArgs, itemHasArgs := strings.Cut(item, " ")
+	cmdPath, cmdArgs, _ := strings.Cut(cmd, " ")
+	if ok, _ := filepath.Match(itemPath, cmdPath); !ok {
+		return false
+	}
+	return !itemHasArgs || strings.TrimSpace(itemArgs) == strings.TrimSpace(cmdArgs)
+}
+
+// RootGrants returns the grants that let u run something as root on this
+// host, in file order.
+func (c *SudoersConfig) RootGrants(u SudoUser) []SudoersGrant {
+	var out []SudoersGrant
+	for _, g := range c.Grants {
+		if !sudoersListMatch(g.Users, u.matches) || !sudoersListMatch(g.Hosts, c.hostMatch) {
+			continue
+		}
+		if g.RunAsSet && !sudoersListMatch(g.RunAsUsers, sudoRootUser.matches) {
+			continue // "(:group)" runs as the invoking user
+		}
+		out = append(out, g)
+	}
+	return out
+}
+
+// AnyCommand reports whether u may run any command as root, returning the
+// deciding grant: the last grant for "ALL", which a later "!ALL" revokes.
+func (c *SudoersConfig) AnyCommand(u SudoUser) (SudoersGrant, bool) {
+	var last SudoersGrant
+	found := false
+	for _, g := range c.RootGrants(u) {
+		if g.Command == "ALL" {
+			last, found = g, true
+		}
+	}
+	return last, found && !last.Negated
+}
+
+// sudoersScopeOrder is the order sudo applies Defaults in, later scopes
+// overriding earlier ones: global, host, user, runas, command.
+var sudoersScopeOrder = []byte{0, '@', ':', '>', '!'}
+
+// NoPasswd reports whether running g needs no password for u: a NOPASSWD
+// tag, or "Defaults !authenticate" that applies to the request, unless the
+// grant is tagged PASSWD. Defaults scoped to this host, to u, to root as
+// run-as target or to g's command count; see sudoersCmndMatch for the last.
+func (c *SudoersConfig) NoPasswd(g SudoersGrant, u SudoUser) bool {
+	for _, t := range g.Tags {
+		switch t {
+		case "NOPASSWD":
+			return true
+		case "PASSWD":
+			return false
+		}
+	}
+	authenticate := true
+	for _, scope := range sudoersScopeOrder {
+		for _, d := range c.Defaults {
+			if d.Name != "authenticate" || d.Scope != scope {
+				continue
+			}
+			applies := true
+			switch scope {
+			case '@':
+				applies = sudoersListMatch(d.Binding, c.hostMatch)
+			case ':':
+				applies = sudoersListMatch(d.Binding, u.matches)
+			case '>':
+				applies = sudoersListMatch(d.Binding, sudoRootUser.matches)
+			case '!':
+				applies = sudoersListMatch(d.Binding, func(item string) bool { return sudoersCmndMatch(item, g.Command) })
+			}
+			if applies {
+				authenticate = !d.Negated
+			}
+		}
+	}
+	return !authenticate
+}
+
+// NoPasswdUsers returns the accounts among users that g names and that run
+// it without a password. applies is false when g names none of them (a
+// directory group, say); nopasswd then holds for a user that no user-scoped
+// Defaults names.
+func (c *SudoersConfig) NoPasswdUsers(g SudoersGrant, users []SudoUser) (names []string, applies, nopasswd bool) {
+	for _, u := range users {
+		if !sudoersListMatch(g.Users, u.matches) {
+			continue
+		}
+		applies = true
+		if c.NoPasswd(g, u) {
+			names = append(names, u.Name)
+		}
+	}
+	if !applies {
+		return nil, false, c.NoPasswd(g, SudoUser{})
+	}
+	return names, true, len(names) > 0
+}
+
+// Default returns the last global Defaults entry for name.
+func (c *SudoersConfig) Default(name string) (SudoersDefault, bool) {
+	var last SudoersDefault
+	found := false
+	for _, d := range c.Defaults {
+		if d.Scope == 0 && d.Name == name {
+			last, found = d, true
+		}
+	}
+	return last, found
+}
+
+// sudoersConfig returns the scan's sudoers model, parsed once.
+func sudoersConfig(req FactRequest) (*SudoersConfig, error) {
+	v, err := req.store.do(req.Context(), kindParsed, SudoersPath, func() (any, error) {
+		return ParseSudoersConfig(SudoersPath, req.ReadFile, req.ReadDir)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*SudoersConfig), nil
+}
+
+// sudoUsers returns the local accounts other than UID 0 with their groups,
+// from /etc/passwd and /etc/group.
+func sudoUsers(req FactRequest) ([]SudoUser, error) {
+	passwd, err := req.ReadFile("/etc/passwd")
+	if err != nil {
+		return nil, fmt.Errorf("read /etc/passwd: %w", err)
+	}
+	groupData, err := req.ReadFile("/etc/group")
+	if err != nil {
+		return nil, fmt.Errorf("read /etc/group: %w", err)
+	}
+
+	groupName := map[int]string{}
+	members := map[string][]int{} // user -> supplementary GIDs
+	for _, line := range strings.Split(string(groupData), "\n") {
+		f := strings.Split(line, ":")
+		if len(f) < 4 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		gid, err := strconv.Atoi(f[2])
+		if err != nil {
+			continue
+		}
+		groupName[gid] = f[0]
+		for _, m := range strings.Split(f[3], ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				members[m] = append(members[m], gid)
+			}
+		}
+	}
+
+	var users []SudoUser
+	for _, line := range strings.Split(string(passwd), "\n") {
+		f := strings.Split(line, ":")
+		if len(f) < 4 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		uid, err1 := strconv.Atoi(f[2])
+		gid, err2 := strconv.Atoi(f[3])
+		if err1 != nil || err2 != nil || uid == 0 {
+			continue
+		}
+		u := SudoUser{Name: f[0], UID: uid, GIDs: append([]int{gid}, members[f[0]]...)}
+		for _, g := range u.GIDs {
+			if name, ok := groupName[g]; ok {
+				u.Groups = append(u.Groups, name)
+			}
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
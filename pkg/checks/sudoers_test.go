@@ -0,0 +1,218 @@
+package checks
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func parseSudoersTest(t *testing.T, files fstest.MapFS) *SudoersConfig {
+	t.Helper()
+	read, readDir := mapFSAccess(files)
+	c, err := ParseSudoersConfig(SudoersPath, read, readDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+var (
+	sudoAlice = SudoUser{Name: "alice", UID: 1000, GIDs: []int{1000, 10}, Groups: []string{"alice", "wheel"}}
+	sudoBob   = SudoUser{Name: "bob", UID: 1001, GIDs: []int{1001}, Groups: []string{"bob"}}
+)
+
+func TestSudoersLogicalLines(t *testing.T) {
+	got := sudoersLogicalLines("a \\\n  b\nc \\\\\nd\\\ne")
+	want := []sudoersLine{{"a    b", 1}, {"c \\\\", 3}, {"d e", 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sudoersLogicalLines = %q, want %q", got, want)
+	}
+}
+
+func TestSudoersStripComment(t *testing.T) {
+	for line, want := range map[string]string{
+		"alice ALL = ALL # admin":    "alice ALL = ALL ",
+		"#1000 ALL = ALL":            "#1000 ALL = ALL",
+		"%#10 ALL = ALL #x":          "%#10 ALL = ALL ",
+		`Defaults prompt="a # b" #c`: `Defaults prompt="a # b" `,
+		`alice ALL = /bin/echo \#x`:  `alice ALL = /bin/echo \#x`,
+	} {
+		if got := sudoersStripComment(line); got != want {
+			t.Errorf("sudoersStripComment(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestParseSudoersGrants(t *testing.T) {
+	c := parseSudoersTest(t, fstest.MapFS{
+		"etc/sudoers": {Data: []byte(
+			"User_Alias ADMINS = alice, \\\n    carol\n" +
+				"Cmnd_Alias SHELLS = /bin/sh, /bin/bash\n" +
+				"Cmnd_Alias PKG = /usr/bin/dnf, !/usr/bin/rpm\n" +
+				"ADMINS ALL = (root) NOPASSWD: /usr/bin/systemctl restart sshd, PASSWD: SHELLS\n" +
+				"%wheel ALL=(ALL:ALL) ALL : web1 = !PKG\n" +
+				"bob ALL = (:adm) /usr/bin/less\n")},
+	})
+	type grant struct {
+		users   string
+		cmd     string
+		negated bool
+		tags    []string
+	}
+	var got []grant
+	for _, g := range c.Grants {
+		got = append(got, grant{g.Users[0] + "..", g.Command, g.Negated, g.Tags})
+	}
+	want := []grant{
+		{"alice..", "/usr/bin/systemctl restart sshd", false, []string{"NOPASSWD"}},
+		{"alice..", "/bin/sh", false, []string{"PASSWD"}},
+		{"alice..", "/bin/bash", false, []string{"PASSWD"}},
+		{"%wheel..", "ALL", false, nil},
+		{"%wheel..", "/usr/bin/dnf", true, nil},
+		{"%wheel..", "/usr/bin/rpm", false, nil},
+		{"bob..", "/usr/bin/less", false, nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("grants:\n got %+v\nwant %+v", got, want)
+	}
+	if g := c.Grants[0]; !reflect.DeepEqual(g.Users, []string{"alice", "carol"}) || g.Line != 5 {
+		t.Errorf("ADMINS grant: users %q line %d, want [alice carol] line 5", g.Users, g.Line)
+	}
+	if g := c.Grants[4]; !reflect.DeepEqual(g.Hosts, []string{"web1"}) {
+		t.Errorf("second section hosts = %q, want [web1]", g.Hosts)
+	}
+}
+
+func TestSudoersAnyCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		user  SudoUser
+		want  bool
+	}{
+		{"group grant", "%wheel ALL = ALL\n", sudoAlice, true},
+		{"other group", "%wheel ALL = ALL\n", sudoBob, false},
+		{"later negation wins", "alice ALL = ALL\nalice ALL = !ALL\n", sudoAlice, false},
+		{"later grant wins", "alice ALL = !ALL\nalice ALL = ALL\n", sudoAlice, true},
+		{"excluded from ALL", "ALL, !alice ALL = ALL\n", sudoAlice, false},
+		{"excluded via negated alias", "User_Alias NOT = !alice\nALL, NOT ALL = ALL\n", sudoAlice, false},
+		{"uid", "#1000 ALL = ALL\n", sudoAlice, true},
+		{"gid", "%#10 ALL = ALL\n", sudoAlice, true},
+		{"other host", "alice db1 = ALL\n", sudoAlice, false},
+		{"this host", "alice web1.example.com = ALL\n", sudoAlice, true},
+		{"runs as a group only", "alice ALL = (:adm) ALL\n", sudoAlice, false},
+		{"runs as another user", "alice ALL = (postgres) ALL\n", sudoAlice, false},
+	}
+	for _, tt := range tests {
+		read, readDir := mapFSAccess(fstest.MapFS{"etc/sudoers": {Data: []byte(tt.rules)}})
+		c, err := ParseSudoersConfig(SudoersPath, read, readDir)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		c.Host = "web1.example.com"
+		if _, got := c.AnyCommand(tt.user); got != tt.want {
+			t.Errorf("%s: AnyCommand = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSudoersNoPasswd(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		user  SudoUser
+		want  bool
+	}{
+		{"tag", "alice ALL = NOPASSWD: ALL\n", sudoAlice, true},
+		{"no tag", "alice ALL = ALL\n", sudoAlice, false},
+		{"global", "Defaults !authenticate\nalice ALL = ALL\n", sudoAlice, true},
+		{"PASSWD tag beats Defaults", "Defaults !authenticate\nalice ALL = PASSWD: ALL\n", sudoAlice, false},
+		{"user scope", "Defaults:alice !authenticate\nalice ALL = ALL\n", sudoAlice, true},
+		{"user scope, other user", "Defaults:bob !authenticate\nalice ALL = ALL\n", sudoAlice, false},
+		{"user scope via alias", "User_Alias OPS = %wheel\nDefaults:OPS !authenticate\nalice ALL = ALL\n", sudoAlice, true},
+		{"user scope overrides global", "Defaults !authenticate\nDefaults:alice authenticate\nalice ALL = ALL\n", sudoAlice, false},
+		{"runas root", "Defaults>root !authenticate\nalice ALL = ALL\n", sudoAlice, true},
+		{"this host", "Defaults@web1 !authenticate\nalice ALL = ALL\n", sudoAlice, true},
+		{"other host", "Defaults@db1 !authenticate\nalice ALL = ALL\n", sudoAlice, false},
+		{"user overrides host", "Defaults@web1 !authenticate\nDefaults:alice authenticate\nalice ALL = ALL\n", sudoAlice, false},
+		{"command scope", "Defaults!/usr/bin/less !authenticate\nalice ALL = /usr/bin/less /var/log/messages\n", sudoAlice, true},
+		{"command scope with other args", "Defaults!/usr/bin/less /etc/hosts !authenticate\nalice ALL = /usr/bin/less /var/log/messages\n", sudoAlice, false},
+		{"command scope glob", "Defaults!/usr/bin/* !authenticate\nalice ALL = /usr/bin/less\n", sudoAlice, true},
+		{"command scope does not cover ALL", "Defaults!/usr/bin/less !authenticate\nalice ALL = ALL\n", sudoAlice, false},
+	}
+	for _, tt := range tests {
+		read, readDir := mapFSAccess(fstest.MapFS{"etc/sudoers": {Data: []byte(tt.rules)}})
+		c, err := ParseSudoersConfig(SudoersPath, read, readDir)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		c.Host = "web1"
+		grants := c.RootGrants(tt.user)
+		if len(grants) == 0 {
+			t.Errorf("%s: no grant for %s", tt.name, tt.user.Name)
+			continue
+		}
+		if got := c.NoPasswd(grants[len(grants)-1], tt.user); got != tt.want {
+			t.Errorf("%s: NoPasswd = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSudoersNoPasswdUsers(t *testing.T) {
+	c := parseSudoersTest(t, fstest.MapFS{
+		"etc/sudoers": {Data: []byte("Defaults:bob !authenticate\nalice, bob ALL = ALL\n%ldap-admins ALL = ALL\n")},
+	})
+	users := []SudoUser{sudoAlice, sudoBob}
+	if names, applies, nopasswd := c.NoPasswdUsers(c.Grants[0], users); !applies || !nopasswd || !reflect.DeepEqual(names, []string{"bob"}) {
+		t.Errorf("NoPasswdUsers(alice, bob) = %q, %v, %v; want [bob], true, true", names, applies, nopasswd)
+	}
+	if names, applies, nopasswd := c.NoPasswdUsers(c.Grants[1], users); applies || nopasswd || names != nil {
+		t.Errorf("NoPasswdUsers(%%ldap-admins) = %q, %v, %v; want nil, false, false", names, applies, nopasswd)
+	}
+}
+
+func TestSudoersIncludes(t *testing.T) {
+	c := parseSudoersTest(t, fstest.MapFS{
+		"etc/sudoers":                   {Data: []byte("Defaults use_pty\n#includedir /etc/sudoers.d\n@include sudoers.local\n")},
+		"etc/sudoers.local":             {Data: []byte("Defaults logfile=/var/log/sudo.log\n")},
+		"etc/sudoers.d/20-ops":          {Data: []byte("Defaults timestamp_timeout=10\n")},
+		"etc/sudoers.d/10-admins":       {Data: []byte("alice ALL = ALL\n")},
+		"etc/sudoers.d/10-admins~":      {Data: []byte("bob ALL = ALL\n")},
+		"etc/sudoers.d/old.rpmsave":     {Data: []byte("bob ALL = ALL\n")},
+		"etc/sudoers.d/subdir/nested":   {Data: []byte("bob ALL = ALL\n")},
+		"etc/sudoers.d/.hidden-include": {Data: []byte("bob ALL = ALL\n")},
+	})
+	want := []string{SudoersPath, "/etc/sudoers.d/10-admins", "/etc/sudoers.d/20-ops", "/etc/sudoers.local"}
+	if !reflect.DeepEqual(c.Files, want) {
+		t.Errorf("Files = %q, want %q", c.Files, want)
+	}
+	if d, ok := c.Default("timestamp_timeout"); !ok || d.Value != "10" || d.File != "/etc/sudoers.d/20-ops" {
+		t.Errorf("Default(timestamp_timeout) = %+v, want 10 from 20-ops", d)
+	}
+	if len(c.Grants) != 1 || c.Grants[0].Users[0] != "alice" {
+		t.Errorf("Grants = %+v, want only alice's", c.Grants)
+	}
+
+	// a missing includedir is skipped, a missing include is an error
+	parseSudoersTest(t, fstest.MapFS{"etc/sudoers": {Data: []byte("@includedir /etc/sudoers.d\n")}})
+	read, readDir := mapFSAccess(fstest.MapFS{"etc/sudoers": {Data: []byte("@include /etc/sudoers.missing\n")}})
+	if _, err := ParseSudoersConfig(SudoersPath, read, readDir); err == nil {
+		t.Error("missing @include: want an error")
+	}
+}
+
+func TestParseSudoersErrors(t *testing.T) {
+	for _, rules := range []string{
+		"alice ALL\n",
+		"alice ALL = (root /bin/sh\n",
+		"Defaults:alice\n",
+		"User_Alias ADMINS\n",
+	} {
+		read, readDir := mapFSAccess(fstest.MapFS{"etc/sudoers": {Data: []byte(rules)}})
+		if _, err := ParseSudoersConfig(SudoersPath, read, readDir); err == nil {
+			t.Errorf("ParseSudoersConfig(%q): want an error", rules)
+		}
+	}
+}
@@ -1,18 +1,55 @@
 package checks
 
+import "strings"
+
 // CheckResult is the final output of a rule check.
 type CheckResult struct {
-	ID          string   `json:"ID"`
-	Title       string   `json:"Title"`
-	Category    string   `json:"Category"`
-	Severity    string   `json:"Severity"`
-	Status      string   `json:"Status"`
-	Observed    string   `json:"Observed"`
-	Expected    string   `json:"Expected"`
-	Evidence    string   `json:"Evidence,omitempty"`
-	Remediation string   `json:"Remediation"`
-	FilePath    string   `json:"FilePath,omitempty"`
-	Tags        []string `json:"Tags,omitempty"`
+	ID          string      `json:"ID"`
+	Title       string      `json:"Title"`
+	Category    string      `json:"Category"`
+	Severity    string      `json:"Severity"`
+	Status      string      `json:"Status"`
+	Observed    Value       `json:"Observed"`
+	Offenders   []string    `json:"Offenders,omitempty"` // elements of a list/map fact that failed the rule
+	Expected    string      `json:"Expected"`
+	Evidence    string      `json:"Evidence,omitempty"`
+	Reason      string      `json:"Reason,omitempty"` // why a rule is "na" or "insufficient_privileges"
+	Remediation string      `json:"Remediation"`
+	FilePath    string      `json:"FilePath,omitempty"`
+	Tags        []string    `json:"Tags,omitempty"`
+	Description string      `json:"Description,omitempty"`
+	Rationale   string      `json:"Rationale,omitempty"`
+	References  *References `json:"References,omitempty"`
+	Mappings    *Mappings   `json:"Mappings,omitempty"`
+	Waiver      *Waiver     `json:"Waiver,omitempty"` // accepted risk matched to a failing result
+	Fix         []FixAction `json:"-"`                // rendered by BuildFixScript
+}
+
+// Waiver is the accepted risk a failing result was matched with (--waivers).
+// While it is valid the result is "waived"; once expired it stays "fail"
+// and Expired is set so reports can call it out.
+type Waiver struct {
+	Owner         string `json:"owner"`
+	Ticket        string `json:"ticket"`
+	Expires       string `json:"expires"` // YYYY-MM-DD, last valid day
+	Justification string `json:"justification"`
+	Expired       bool   `json:"expired"`
+	Source        string `json:"source"` // file:line of the waiver
+}
+
+// References point at the sources behind a rule.
+type References struct {
+	URLs []string `yaml:"urls" json:"urls,omitempty"`
+	CIS  string   `yaml:"cis" json:"cis,omitempty"`   // benchmark section, e.g. "5.1.1"
+	STIG string   `yaml:"stig" json:"stig,omitempty"` // DISA STIG rule ID
+	CCE  string   `yaml:"cce" json:"cce,omitempty"`   // Common Configuration Enumeration ID
+}
+
+// Mappings relate a rule to control frameworks.
+type Mappings struct {
+	NIST80053   []string `yaml:"nist_800_53" json:"nist_800_53,omitempty"`
+	PCIDSS      []string `yaml:"pci_dss" json:"pci_dss,omitempty"`
+	MITREATTACK []string `yaml:"mitre_attack" json:"mitre_attack,omitempty"`
 }
 
 // Rule describes a rule loaded from YAML.
@@ -23,9 +60,47 @@ type Rule struct {
 	Severity    string   `yaml:"severity"`
 	Fact        string   `yaml:"fact"`
 	Expected    string   `yaml:"expected"`
-	ExpectedAll []string `yaml:"expected_all"` // ❤️ matches rules.yaml now
+	ExpectedAll []string `yaml:"expected_all"` // ❤️ matches builtin.yaml now
 	Remediation string   `yaml:"remediation"`
 
+	// Description says what the rule checks and Rationale why it matters;
+	// both reach the reports and "redcheck explain".
+	Description string      `yaml:"description"`
+	Rationale   string      `yaml:"rationale"`
+	References  *References `yaml:"references"`
+	Mappings    *Mappings   `yaml:"mappings"`
+
+	// Fix lists typed remediation actions for --emit-fix (see FixAction).
+	Fix []FixAction `yaml:"fix"`
+
+	// Operator selects how the observed value is compared with Expected:
+	// eq, ne, regex, not_regex, in, not_in, lt, le, gt, ge, contains,
+	// not_contains, empty, not_empty, and for list facts count_eq ...
+	// count_ge (or "count <=" etc.), all_match, any_match, none_match.
+	// Empty means exact match on Expected. in/not_in read their set from
+	// Values (or a comma-separated Expected).
+	Operator string   `yaml:"operator"`
+	Values   []string `yaml:"values"`
+
+	// Optional condition tree over several facts. These combine with the
+	// single-fact expectation above (if any) as an implicit AND, e.g.
+	//   all:
+	//     - { fact: pkg.firewalld_installed, expected: present }
+	//     - { fact: svc.firewalld_state, expected: enabled_active }
+	All []Condition `yaml:"all"`
+	Any []Condition `yaml:"any"`
+	Not *Condition  `yaml:"not"`
+
+	// When is an applicability precondition. If it does not hold the rule is
+	// reported as "na" (excluded from scoring) and its facts aren't collected:
+	//   when: { fact: "pkg.installed:openssh-server", expected: "present" }
+	When *Condition `yaml:"when"`
+
+	// RequiresRoot marks rules whose facts can't be read without root
+	// (e.g. /etc/sudoers). An unprivileged scan reports them as
+	// "insufficient_privileges" instead of collecting partial facts.
+	RequiresRoot bool `yaml:"requires_root"`
+
 	// YAML can provide either:
 	//   file:  "/etc/ssh/sshd_config"
 	//   files: ["/etc/ssh/sshd_config", "/etc/issue.net"]
@@ -33,6 +108,34 @@ type Rule struct {
 	Files    []string `yaml:"files,omitempty"` // optional list of files
 
 	Tags []string `yaml:"tags"`
+
+	// CISLevel (1 or 2) and CISProfiles ("server", "workstation") place a
+	// CIS rule in the benchmark's applicability profiles; no profiles means
+	// both. A Level 2 profile includes every Level 1 rule.
+	CISLevel    int      `yaml:"cis_level"`
+	CISProfiles []string `yaml:"cis_profile"`
+
+	// Vars are defaults for ${name} placeholders in the rule's facts and
+	// expectations; a tailoring profile's vars override them.
+	Vars map[string]string `yaml:"vars"`
+
+	// Source is "file:line" of the rule's definition, set by the loader.
+	Source string `yaml:"-"`
+}
+
+// Condition returns the rule's root condition: its own fact expectation
+// together with any all/any/not branches.
+func (r Rule) Condition() Condition {
+	return Condition{
+		Fact:        r.Fact,
+		Expected:    r.Expected,
+		ExpectedAll: r.ExpectedAll,
+		Operator:    r.Operator,
+		Values:      r.Values,
+		All:         r.All,
+		Any:         r.Any,
+		Not:         r.Not,
+	}
 }
 
 // HasTag checks whether the rule has a specific tag.
@@ -45,6 +148,40 @@ func (r Rule) HasTag(tag string) bool {
 	return false
 }
 
+// InCISProfile reports whether the rule belongs to the CIS profile of the
+// given level (0 = any) and profile name ("" = any).
+func (r Rule) InCISProfile(level int, profile string) bool {
+	if r.CISLevel == 0 || (level > 0 && r.CISLevel > level) {
+		return false
+	}
+	if profile == "" || len(r.CISProfiles) == 0 {
+		return true
+	}
+	for _, p := range r.CISProfiles {
+		if strings.EqualFold(p, profile) {
+			return true
+		}
+	}
+	return false
+}
+
+// CISControl returns the benchmark control a rule or result ID refers to:
+// "CIS-1.1.2.2-tmp" → "1.1.2.2". Non-CIS IDs return "".
+func CISControl(id string) string {
+	ctl, ok := strings.CutPrefix(id, "CIS-")
+	if !ok {
+		return ""
+	}
+	if i := strings.IndexByte(ctl, '-'); i >= 0 {
+		ctl = ctl[:i]
+	}
+	return ctl
+}
+
+// HasFix reports whether --emit-fix has remediation commands for the rule
+// (rather than the generic "no automatic remediation" notice).
+func (r Rule) HasFix() bool { return len(r.Fix) > 0 }
+
 // IsPE identifies privilege-escalation / recon-style rules.
 func (r Rule) IsPE() bool {
 	for _, t := range r.Tags {
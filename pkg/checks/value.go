@@ -0,0 +1,230 @@
+package checks
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of a fact value.
+type Kind string
+
+// Fact value kinds.
+const (
+	KindString Kind = "string"
+	KindInt    Kind = "int"
+	KindBool   Kind = "bool"
+	KindList   Kind = "list"
+	KindMap    Kind = "map"
+)
+
+// Value is the typed value of a fact: a string, int, bool, list or map.
+// The zero Value is the empty string.
+//
+// Rules written against the old string facts keep working: String renders
+// every kind the way collectors used to flatten it (bools as "true"/"false",
+// lists comma-joined), and eq/regex/in compare that form. List operators
+// (count, all_match, ...) and report offenders use the elements instead.
+type Value struct {
+	kind Kind
+	s    string
+	n    int64
+	b    bool
+	list []Value
+	m    map[string]Value
+}
+
+// StringValue returns a string fact value.
+func StringValue(s string) Value { return Value{kind: KindString, s: s} }
+
+// IntValue returns an integer fact value.
+func IntValue(n int64) Value { return Value{kind: KindInt, n: n} }
+
+// BoolValue returns a boolean fact value.
+func BoolValue(b bool) Value { return Value{kind: KindBool, b: b} }
+
+// ListValue returns a list fact value; nil is an empty list.
+func ListValue(items []Value) Value { return Value{kind: KindList, list: items} }
+
+// StringList returns a list of strings, e.g. offending paths.
+func StringList(items []string) Value {
+	list := make([]Value, len(items))
+	for i, s := range items {
+		list[i] = StringValue(s)
+	}
+	return ListValue(list)
+}
+
+// MapValue returns a map fact value.
+func MapValue(m map[string]Value) Value { return Value{kind: KindMap, m: m} }
+
+// ValueOf converts decoded JSON (string, float64, bool, nil, []any,
+// map[string]any) to a Value. Whole numbers become ints; anything else is
+// rendered as a string.
+func ValueOf(v any) Value {
+	switch v := v.(type) {
+	case nil:
+		return StringValue("")
+	case string:
+		return StringValue(v)
+	case bool:
+		return BoolValue(v)
+	case int:
+		return IntValue(int64(v))
+	case int64:
+		return IntValue(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return IntValue(int64(v))
+		}
+		return StringValue(strconv.FormatFloat(v, 'g', -1, 64))
+	case []any:
+		list := make([]Value, len(v))
+		for i, e := range v {
+			list[i] = ValueOf(e)
+		}
+		return ListValue(list)
+	case map[string]any:
+		m := make(map[string]Value, len(v))
+		for k, e := range v {
+			m[k] = ValueOf(e)
+		}
+		return MapValue(m)
+	case Value:
+		return v
+	}
+	b, _ := json.Marshal(v)
+	return StringValue(string(b))
+}
+
+// Kind returns the value's kind.
+func (v Value) Kind() Kind {
+	if v.kind == "" {
+		return KindString
+	}
+	return v.kind
+}
+
+// String renders the value as rules compared it before facts were typed:
+// lists comma-joined, maps as sorted key=value pairs.
+func (v Value) String() string {
+	switch v.Kind() {
+	case KindInt:
+		return strconv.FormatInt(v.n, 10)
+	case KindBool:
+		return strconv.FormatBool(v.b)
+	case KindList, KindMap:
+		return strings.Join(v.Items(), ",")
+	}
+	return v.s
+}
+
+// Int returns the value as an integer: an int, or a string holding one.
+func (v Value) Int() (int64, bool) {
+	switch v.Kind() {
+	case KindInt:
+		return v.n, true
+	case KindString:
+		n, err := strconv.ParseInt(strings.TrimSpace(v.s), 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// List returns the elements of a list value, or nil.
+func (v Value) List() []Value { return v.list }
+
+// Map returns the entries of a map value, or nil.
+func (v Value) Map() map[string]Value { return v.m }
+
+// Items returns the elements operators and reports work on: list
+// elements, "key=value" map entries in key order, or the value itself for
+// a non-empty scalar.
+func (v Value) Items() []string {
+	switch v.Kind() {
+	case KindList:
+		out := make([]string, len(v.list))
+		for i, e := range v.list {
+			out[i] = e.String()
+		}
+		return out
+	case KindMap:
+		keys := make([]string, 0, len(v.m))
+		for k := range v.m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]string, len(keys))
+		for i, k := range keys {
+			out[i] = k + "=" + v.m[k].String()
+		}
+		return out
+	}
+	if v.Empty() {
+		return nil
+	}
+	return []string{v.String()}
+}
+
+// Len is the number of Items.
+func (v Value) Len() int {
+	switch v.Kind() {
+	case KindList:
+		return len(v.list)
+	case KindMap:
+		return len(v.m)
+	}
+	return len(v.Items())
+}
+
+// Empty reports whether a list or map has no entries, or a string is
+// blank. Ints and bools are never empty.
+func (v Value) Empty() bool {
+	switch v.Kind() {
+	case KindList:
+		return len(v.list) == 0
+	case KindMap:
+		return len(v.m) == 0
+	case KindString:
+		return strings.TrimSpace(v.s) == ""
+	}
+	return false
+}
+
+// IsCollection reports whether v is a list or a map.
+func (v Value) IsCollection() bool {
+	return v.Kind() == KindList || v.Kind() == KindMap
+}
+
+// MarshalJSON encodes the value as the matching JSON type.
+func (v Value) MarshalJSON() ([]byte, error) {
+	switch v.Kind() {
+	case KindInt:
+		return json.Marshal(v.n)
+	case KindBool:
+		return json.Marshal(v.b)
+	case KindList:
+		if v.list == nil {
+			return []byte("[]"), nil
+		}
+		return json.Marshal(v.list)
+	case KindMap:
+		if v.m == nil {
+			return []byte("{}"), nil
+		}
+		return json.Marshal(v.m)
+	}
+	return json.Marshal(v.s)
+}
+
+// UnmarshalJSON decodes any JSON value, so reports can be read back.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*v = ValueOf(raw)
+	return nil
+}
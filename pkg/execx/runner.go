@@ -3,26 +3,89 @@ package execx
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"os/exec"
+	"syscall"
 	"time"
 )
 
+// Runner executes external commands. The context carries the deadline and
+// cancellation: when it is done the process is killed.
 type Runner interface {
-	Run(cmd string, args []string, timeout time.Duration) (string, string, int, error)
+	Run(ctx context.Context, cmd string, args []string) (string, string, int, error)
 }
 
+// EnvRunner is a Runner that can also run a command with exactly the given
+// environment instead of inheriting the caller's.
+type EnvRunner interface {
+	Runner
+	RunEnv(ctx context.Context, env []string, cmd string, args []string) (string, string, int, error)
+}
+
+// MaxOutput caps what a command may write to stdout and to stderr; the
+// rest is discarded and Run returns ErrOutputLimit. Facts never need more,
+// and a runaway or hostile script must not exhaust the scanner's memory.
+const MaxOutput = 8 << 20
+
+// ErrOutputLimit means a command wrote more than MaxOutput bytes.
+var ErrOutputLimit = errors.New("command output exceeds the limit")
+
 type LocalRunner struct{}
 
-func (LocalRunner) Run(cmd string, args []string, timeout time.Duration) (string, string, int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+func (r LocalRunner) Run(ctx context.Context, cmd string, args []string) (string, string, int, error) {
+	return r.RunEnv(ctx, nil, cmd, args)
+}
+
+// RunEnv runs cmd with env as its whole environment; nil inherits ours.
+func (LocalRunner) RunEnv(ctx context.Context, env []string, cmd string, args []string) (string, string, int, error) {
 	c := exec.CommandContext(ctx, cmd, args...)
-	var out, errb bytes.Buffer
-	c.Stdout, c.Stderr = &out, &errb
+	if env != nil {
+		c.Env = env
+	}
+	out, errb := &cappedBuffer{max: MaxOutput}, &cappedBuffer{max: MaxOutput}
+	c.Stdout, c.Stderr = out, errb
+
+	// Run in its own process group and kill the whole group on cancellation,
+	// so wrapper scripts don't leave their children running.
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	c.Cancel = func() error {
+		return syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	}
+	c.WaitDelay = time.Second
+
 	err := c.Run()
-	code := 0
+	if ctxErr := ctx.Err(); ctxErr != nil && err != nil {
+		// report why the process was killed rather than "signal: killed"
+		err = ctxErr
+	}
+	if err == nil && (out.truncated || errb.truncated) {
+		err = fmt.Errorf("%w (%d bytes)", ErrOutputLimit, MaxOutput)
+	}
+	code := -1 // never started
 	if c.ProcessState != nil {
 		code = c.ProcessState.ExitCode()
 	}
 	return out.String(), errb.String(), code, err
 }
+
+// cappedBuffer keeps the first max bytes written and drops the rest without
+// failing the write, so the command is not killed by a broken pipe.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) String() string { return b.buf.String() }
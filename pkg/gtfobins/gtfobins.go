@@ -0,0 +1,171 @@
+// Package gtfobins holds the offline GTFOBins-style dataset recon facts
+// match sudo grants, SUID/SGID files and file capabilities against: which
+// binaries escalate privileges in which context, and how.
+package gtfobins
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// APIVersion matches the rule packs' apiVersion.
+	APIVersion = "redcheck/v1"
+	// Kind is the document kind of a dataset.
+	Kind = "GTFOBins"
+)
+
+// Contexts a binary can be abused in.
+const (
+	Sudo         = "sudo"
+	SUID         = "suid"
+	Capabilities = "capabilities"
+)
+
+//go:embed gtfobins.yaml
+var builtin []byte
+
+// Dataset is one GTFOBins document, keyed by binary name.
+type Dataset struct {
+	APIVersion string           `yaml:"apiVersion"`
+	Kind       string           `yaml:"kind"`
+	Metadata   Metadata         `yaml:"metadata"`
+	Binaries   map[string]Entry `yaml:"binaries"`
+}
+
+// Metadata names and versions a dataset.
+type Metadata struct {
+	Name        string `yaml:"name"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// Entry is what a binary gives away in each context; nil when it does not.
+type Entry struct {
+	Sudo         *Technique `yaml:"sudo,omitempty"`
+	SUID         *Technique `yaml:"suid,omitempty"`
+	Capabilities *Technique `yaml:"capabilities,omitempty"`
+}
+
+// Technique is the GTFOBins function (shell, file-read, ...) with one way
+// to use it.
+type Technique struct {
+	Name    string `yaml:"technique"`
+	Example string `yaml:"example,omitempty"`
+}
+
+func (t Technique) String() string {
+	if t.Example == "" {
+		return t.Name
+	}
+	return t.Name + ": " + t.Example
+}
+
+var (
+	builtinOnce sync.Once
+	builtinSet  *Dataset
+	builtinErr  error
+)
+
+// Builtin returns the dataset embedded in the binary.
+func Builtin() (*Dataset, error) {
+	builtinOnce.Do(func() { builtinSet, builtinErr = Parse(builtin, "built-in gtfobins.yaml") })
+	return builtinSet, builtinErr
+}
+
+// Load reads a dataset file; an empty file name means the embedded one.
+func Load(file string) (*Dataset, error) {
+	if file == "" {
+		return Builtin()
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, file)
+}
+
+// Parse decodes and validates one dataset document; name labels errors.
+func Parse(data []byte, name string) (*Dataset, error) {
+	var d Dataset
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&d); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if d.APIVersion != APIVersion || d.Kind != Kind {
+		return nil, fmt.Errorf("%s: want apiVersion %s and kind %s, got %q/%q", name, APIVersion, Kind, d.APIVersion, d.Kind)
+	}
+	if d.Metadata.Version == "" {
+		return nil, fmt.Errorf("%s: metadata.version is required", name)
+	}
+	for bin, e := range d.Binaries {
+		for ctx, t := range map[string]*Technique{Sudo: e.Sudo, SUID: e.SUID, Capabilities: e.Capabilities} {
+			if t != nil && t.Name == "" {
+				return nil, fmt.Errorf("%s: %s.%s: technique is required", name, bin, ctx)
+			}
+		}
+	}
+	return &d, nil
+}
+
+// String is "name version" for evidence lines.
+func (d *Dataset) String() string {
+	return d.Metadata.Name + " " + d.Metadata.Version
+}
+
+// Names lists the binaries in d, sorted.
+func (d *Dataset) Names() []string {
+	names := make([]string, 0, len(d.Binaries))
+	for n := range d.Binaries {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the technique the binary at file gives away in context.
+// Versioned names fall back to the plain one: python3.11 and python3 match
+// python, vim.basic matches vim.
+func (d *Dataset) Lookup(file, context string) (Technique, bool) {
+	for _, name := range candidates(path.Base(file)) {
+		e, ok := d.Binaries[name]
+		if !ok {
+			continue
+		}
+		var t *Technique
+		switch context {
+		case Sudo:
+			t = e.Sudo
+		case SUID:
+			t = e.SUID
+		case Capabilities:
+			t = e.Capabilities
+		}
+		if t != nil {
+			return *t, true
+		}
+		return Technique{}, false
+	}
+	return Technique{}, false
+}
+
+func candidates(base string) []string {
+	out := []string{base}
+	if i := strings.IndexByte(base, '.'); i > 0 {
+		base = base[:i]
+		out = append(out, base)
+	}
+	if trimmed := strings.TrimRight(base, "0123456789"); trimmed != "" && trimmed != base {
+		out = append(out, trimmed)
+	}
+	return out
+}
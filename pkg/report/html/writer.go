@@ -5,7 +5,9 @@ import (
 	"os"
 	"sort"
 
+	"github.com/Shunsuiky0raku/redcheck/pkg/benchmark"
 	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+	"github.com/Shunsuiky0raku/redcheck/pkg/rules"
 	"github.com/Shunsuiky0raku/redcheck/pkg/scoring"
 )
 
@@ -14,6 +16,7 @@ type ViewModel struct {
 	Time          string
 	Scores        scoring.Scores
 	Results       []checks.CheckResult
+	NotApplicable []checks.CheckResult
 	TopFixes      []checks.CheckResult
 	Version       string
 	Commit        string
@@ -23,10 +26,25 @@ type ViewModel struct {
 	Jobs          int
 	Timeout       string
 	IsRoot        bool
+	Tailoring     *rules.Tailoring
+	Waivers       []checks.CheckResult // results matched by a waiver, valid or expired
+	Expired       int                  // waivers that have run out
+	Coverage      *benchmark.Coverage
 }
 
 // IMPORTANT: the HTML template must be a Go raw string (backticks)
-const tpl = `<!doctype html>
+const tpl = `{{define "about"}}{{if or .Description .Rationale .References .Mappings}}
+<div class="small"><details><summary>Why it matters</summary>
+  {{if .Description}}<div>{{.Description}}</div>{{end}}
+  {{if .Rationale}}<div><i>{{.Rationale}}</i></div>{{end}}
+  {{with .References}}<div>References:{{if .CIS}} CIS {{.CIS}}{{end}}{{if .STIG}} &middot; STIG {{.STIG}}{{end}}{{if .CCE}} &middot; {{.CCE}}{{end}}{{range .URLs}} &middot; <a href="{{.}}">{{.}}</a>{{end}}</div>{{end}}
+  {{with .Mappings}}
+    {{if .NIST80053}}<div>NIST 800-53: {{range $i, $m := .NIST80053}}{{if $i}}, {{end}}{{$m}}{{end}}</div>{{end}}
+    {{if .PCIDSS}}<div>PCI DSS: {{range $i, $m := .PCIDSS}}{{if $i}}, {{end}}{{$m}}{{end}}</div>{{end}}
+    {{if .MITREATTACK}}<div>MITRE ATT&amp;CK: {{range $i, $m := .MITREATTACK}}{{if $i}}, {{end}}{{$m}}{{end}}</div>{{end}}
+  {{end}}
+</details></div>
+{{end}}{{end}}{{define "observed"}}{{if .Offenders}}{{len .Offenders}} offender(s):<ul class="offenders">{{range .Offenders}}<li><code>{{.}}</code></li>{{end}}</ul>{{else}}<code>{{.Observed}}</code>{{end}}{{end}}<!doctype html>
 <meta charset="utf-8">
 <title>RedCheck Report</title>
 <style>
@@ -34,11 +52,12 @@ body{font-family:system-ui,-apple-system,Segoe UI,Roboto,Ubuntu,Helvetica,Arial,
 h1,h2{margin:0 0 8px}
 .card{border:1px solid #eee;border-radius:12px;padding:16px;margin:12px 0}
 .badge{display:inline-block;padding:2px 8px;border-radius:999px;font-size:12px}
-.pass{background:#e8f5e9} .fail{background:#ffebee} .error{background:#fff3e0} .na{background:#eceff1}
+.pass{background:#e8f5e9} .fail{background:#ffebee} .error{background:#fff3e0} .na{background:#eceff1} .insufficient_privileges{background:#ede7f6} .waived{background:#e3f2fd} .not_automated{background:#fafafa;border:1px dashed #ccc}
 .row{display:flex;gap:12px;flex-wrap:wrap}
 .bar{height:10px;background:#eee;border-radius:6px;overflow:hidden}
 .fill{height:100%;background:#4caf50}
 .small{color:#555;font-size:12px}
+.offenders{margin:2px 0;padding-left:18px;max-height:160px;overflow:auto}
 table{width:100%;border-collapse:collapse}
 th,td{border-top:1px solid #eee;padding:8px;text-align:left;vertical-align:top}
 code{background:#f6f8fa;padding:2px 4px;border-radius:4px}
@@ -156,11 +175,17 @@ function updateVisibleCount(){
 
 {{if not .IsRoot}}
 <div class="warn">
-  <b>Warning:</b> report generated as non-root. Some checks may be incomplete or marked <code>na</code>/<code>error</code>.
+  <b>Warning:</b> report generated as non-root. Checks that need root are marked <code>insufficient_privileges</code> and excluded from the score.
   For full coverage run with root privileges (e.g., <code>sudo ./redcheck scan ...</code>).
 </div>
 {{end}}
 
+{{if .Expired}}
+<div class="warn">
+  <b>Warning:</b> {{.Expired}} waiver(s) have expired; those findings are counted as failures again. See <a href="#waivers">Waivers</a>.
+</div>
+{{end}}
+
 <div style="margin:8px 0">
   <button onclick="copyFixes()">Copy all remediation commands</button>
 </div>
@@ -206,8 +231,9 @@ function updateVisibleCount(){
   {{range .TopFixes}}
     <div class="fix-item" data-category="{{.Category}}" data-severity="{{.Severity}}" data-title="{{.Title}}" data-ease="{{if eq .Category "Services"}}low{{else if eq .Category "Auth"}}medium{{else if eq .Category "FS_Perms"}}low{{else}}medium{{end}}">
       <b>{{.Title}}</b> <span class="badge {{.Status}}">{{.Status}}</span><span class="category-badge cat-{{.Category}}">{{.Category}}</span>
-      <div class="small">Observed: <code>{{.Observed}}</code> → Expected: <code>{{.Expected}}</code></div>
+      <div class="small">Observed: {{template "observed" .}} → Expected: <code>{{.Expected}}</code></div>
       <div class="small fix-remediation">Remediation: {{.Remediation}}</div>
+      {{template "about" .}}
       {{if .Evidence}}
         <div class="small"><details><summary>Evidence</summary><pre>{{.Evidence}}</pre></details></div>
       {{end}}
@@ -231,10 +257,10 @@ function updateVisibleCount(){
       {{range .Results}}
       <tr>
         <td><code>{{.ID}}</code></td>
-        <td>{{.Title}}</td>
+        <td>{{.Title}}{{template "about" .}}</td>
         <td>{{.Category}}</td>
         <td><span class="badge {{.Status}}">{{.Status}}</span></td>
-        <td class="small"><code>{{.Observed}}</code> → <code>{{.Expected}}</code></td>
+        <td class="small">{{template "observed" .}} → <code>{{.Expected}}</code>{{if .Reason}}<br>{{.Reason}}{{end}}{{with .Waiver}}{{if .Expired}}<br><b>waiver expired {{.Expires}}</b> ({{.Ticket}}){{end}}{{end}}</td>
         <td class="small rem">
           {{.Remediation}}
           {{if .Evidence}}
@@ -247,6 +273,93 @@ function updateVisibleCount(){
   </table>
 </div>
 
+{{with .Tailoring}}
+<div class="card">
+  <h2>Tailoring profile</h2>
+  <div class="small">Profile <b>{{.Profile}}</b> ({{.File}}){{if .Vars}} &middot; vars:{{range $k, $v := .Vars}} <code>{{$k}}={{$v}}</code>{{end}}{{end}}</div>
+  {{if .Overrides}}
+  <table>
+    <thead><tr><th>Rule</th><th>Change</th><th>Reason</th></tr></thead>
+    <tbody>
+      {{range .Overrides}}
+      <tr><td><code>{{.ID}}</code></td><td>{{.Change}}</td><td class="small">{{.Reason}}</td></tr>
+      {{end}}
+    </tbody>
+  </table>
+  {{else}}
+  <div class="small">No rule overrides.</div>
+  {{end}}
+</div>
+{{end}}
+
+{{with .Coverage}}
+<div class="card">
+  <h2>Benchmark coverage</h2>
+  <div class="small">{{.Benchmark}} {{.Version}} &middot; {{if .Level}}Level {{.Level}}{{else}}all levels{{end}}{{if .Profile}} &middot; {{.Profile}}{{end}} &middot; {{.Controls}} controls</div>
+  <div style="margin:8px 0">
+    Implemented: <b>{{.Implemented}}</b> &middot; Passed: <b>{{.Passed}}</b> &middot; Failed: <b>{{.Failed}}</b> &middot; Not automated: <b>{{.NotAutomated}}</b>
+  </div>
+  <details><summary>Controls</summary>
+  <table>
+    <thead><tr><th>Control</th><th>Title</th><th>Status</th><th>Rules</th></tr></thead>
+    <tbody>
+      {{range .Details}}
+      <tr>
+        <td><code>{{.ID}}</code></td>
+        <td>{{.Title}}</td>
+        <td><span class="badge {{.Status}}">{{.Status}}</span></td>
+        <td class="small">{{range $i, $r := .Rules}}{{if $i}}, {{end}}<code>{{$r}}</code>{{end}}</td>
+      </tr>
+      {{end}}
+    </tbody>
+  </table>
+  </details>
+</div>
+{{end}}
+
+{{if .Waivers}}
+<div class="card" id="waivers">
+  <h2>Waivers</h2>
+  <div class="small">Accepted risks. Waived results are excluded from the score until the waiver expires.</div>
+  <table>
+    <thead><tr><th>ID</th><th>Title</th><th>Status</th><th>Owner</th><th>Ticket</th><th>Expires</th><th>Justification</th></tr></thead>
+    <tbody>
+      {{range .Waivers}}
+      <tr>
+        <td><code>{{.ID}}</code></td>
+        <td>{{.Title}}</td>
+        <td><span class="badge {{.Status}}">{{if .Waiver.Expired}}expired{{else}}{{.Status}}{{end}}</span></td>
+        <td>{{.Waiver.Owner}}</td>
+        <td>{{.Waiver.Ticket}}</td>
+        <td>{{.Waiver.Expires}}</td>
+        <td class="small">{{.Waiver.Justification}}</td>
+      </tr>
+      {{end}}
+    </tbody>
+  </table>
+</div>
+{{end}}
+
+{{if .NotApplicable}}
+<div class="card">
+  <h2>Not applicable</h2>
+  <div class="small">These rules did not apply to this host and are excluded from the score.</div>
+  <table>
+    <thead><tr><th>ID</th><th>Title</th><th>Category</th><th>Reason</th></tr></thead>
+    <tbody>
+      {{range .NotApplicable}}
+      <tr>
+        <td><code>{{.ID}}</code></td>
+        <td>{{.Title}}</td>
+        <td>{{.Category}}</td>
+        <td class="small">{{.Reason}}</td>
+      </tr>
+      {{end}}
+    </tbody>
+  </table>
+</div>
+{{end}}
+
 <div class="footer">
   Generated by RedCheck v{{.Version}} (commit {{.Commit}}, built {{.BuildDate}})
 </div>
@@ -258,14 +371,29 @@ func Write(
 	results []checks.CheckResult,
 	builtIn, external, jobs int,
 	timeout string, isRoot bool,
+	tailoring *rules.Tailoring,
+	coverage *benchmark.Coverage,
 	version, commit, buildDate string,
 ) error {
-	// pick Top 5 fails by severity
+	// pick Top 5 fails by severity; N/A rules get their own section
 	fail := make([]checks.CheckResult, 0, len(results))
+	var applicable, notApplicable, waived []checks.CheckResult
+	expired := 0
 	for _, r := range results {
+		if r.Waiver != nil {
+			waived = append(waived, r)
+			if r.Waiver.Expired {
+				expired++
+			}
+		}
 		if r.Status == "fail" {
 			fail = append(fail, r)
 		}
+		if r.Status == "na" {
+			notApplicable = append(notApplicable, r)
+		} else {
+			applicable = append(applicable, r)
+		}
 	}
 	sevRank := map[string]int{"Critical": 0, "High": 1, "Medium": 2, "Low": 3}
 	sort.Slice(fail, func(i, j int) bool {
@@ -285,7 +413,8 @@ func Write(
 		Hostname:      hostname,
 		Time:          tstamp,
 		Scores:        scores,
-		Results:       results,
+		Results:       applicable,
+		NotApplicable: notApplicable,
 		TopFixes:      fail,
 		Version:       version,
 		Commit:        commit,
@@ -295,6 +424,10 @@ func Write(
 		Jobs:          jobs,
 		Timeout:       timeout,
 		IsRoot:        isRoot,
+		Tailoring:     tailoring,
+		Waivers:       waived,
+		Expired:       expired,
+		Coverage:      coverage,
 	}
 
 	t := template.Must(template.New("r").Parse(tpl))
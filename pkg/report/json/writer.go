@@ -4,12 +4,20 @@ import (
 	"encoding/json"
 	"os"
 
+	"github.com/Shunsuiky0raku/redcheck/pkg/benchmark"
 	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+	"github.com/Shunsuiky0raku/redcheck/pkg/rules"
 	"github.com/Shunsuiky0raku/redcheck/pkg/scoring"
 )
 
+// SchemaVersion is the layout of the report. 2: "Observed" keeps the
+// fact's JSON type (string, number, bool, array, object) and failing list
+// rules carry "Offenders"; in 1 Observed was always a string.
+const SchemaVersion = 2
+
 type Report struct {
 	Meta struct {
+		Schema    int    `json:"schema"`
 		Version   string `json:"version"`
 		Commit    string `json:"commit"`
 		BuildDate string `json:"build_date"`
@@ -19,30 +27,64 @@ type Report struct {
 		Time     string `json:"time"`
 	} `json:"host"`
 	Scores  scoring.Scores       `json:"scores"`
+	Summary map[string]int       `json:"summary"` // result count per status
 	Results []checks.CheckResult `json:"results"`
+	// NotApplicable holds rules whose "when" precondition did not hold;
+	// they are excluded from Results and from scoring.
+	NotApplicable []checks.CheckResult `json:"not_applicable,omitempty"`
+	// Tailoring records the --profile used and every override it applied.
+	Tailoring *rules.Tailoring `json:"tailoring,omitempty"`
+	// ExpiredWaivers lists failures whose waiver has expired; they remain
+	// in Results as "fail".
+	ExpiredWaivers []ExpiredWaiver `json:"expired_waivers,omitempty"`
+	// Coverage maps results onto the --benchmark controls in scope.
+	Coverage *benchmark.Coverage `json:"coverage,omitempty"`
+}
+
+// ExpiredWaiver names the rule a lapsed waiver was written for.
+type ExpiredWaiver struct {
+	ID string `json:"id"`
+	checks.Waiver
 }
 
 func Write(
 	path string,
 	results []checks.CheckResult,
+	tailoring *rules.Tailoring,
+	coverage *benchmark.Coverage,
 	hostname, tstamp string,
 	version, commit, buildDate string,
 ) error {
 	var r Report
 	// meta
+	r.Meta.Schema = SchemaVersion
 	r.Meta.Version = version
 	r.Meta.Commit = commit
 	r.Meta.BuildDate = buildDate
 	// host
 	r.Host.Hostname = hostname
 	r.Host.Time = tstamp
+	r.Tailoring = tailoring
+	r.Coverage = coverage
 	// scores
 	resIface := make([]scoring.Result, len(results))
 	for i := range results {
 		resIface[i] = results[i]
 	}
 	r.Scores = scoring.Compute(resIface)
-	r.Results = results
+	r.Summary = map[string]int{}
+	r.Results = []checks.CheckResult{}
+	for _, res := range results {
+		r.Summary[res.Status]++
+		if res.Status == "na" {
+			r.NotApplicable = append(r.NotApplicable, res)
+			continue
+		}
+		if res.Waiver != nil && res.Waiver.Expired {
+			r.ExpiredWaivers = append(r.ExpiredWaivers, ExpiredWaiver{res.ID, *res.Waiver})
+		}
+		r.Results = append(r.Results, res)
+	}
 
 	b, err := json.MarshalIndent(r, "", "  ")
 	if err != nil {
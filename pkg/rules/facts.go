@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+)
+
+// RegisterFacts validates the script facts defined in packs and registers
+// a collector for each, so rules can reference them like built-in facts.
+// Nothing is registered when any definition is invalid or a name is taken.
+func RegisterFacts(packs ...*Pack) error {
+	if diags := checkFacts(packs); len(diags) > 0 {
+		return &Error{diags}
+	}
+	registerFacts(packs)
+	return nil
+}
+
+// checkFacts reports invalid definitions and names defined twice or
+// already registered.
+func checkFacts(packs []*Pack) []Diagnostic {
+	var diags []Diagnostic
+	seen := map[string]string{} // name -> source
+	for _, p := range packs {
+		for _, f := range p.Facts {
+			for _, err := range f.Validate() {
+				diags = append(diags, Diagnostic{f.Source, err.Error()})
+			}
+			if prev, dup := seen[f.Name]; dup {
+				diags = append(diags, Diagnostic{f.Source, fmt.Sprintf("fact %q already defined at %s", f.Name, prev)})
+				continue
+			}
+			seen[f.Name] = f.Source
+			if c, ok := checks.LookupCollector(f.Name); ok && c.Name() == f.Name {
+				diags = append(diags, Diagnostic{f.Source, fmt.Sprintf("fact %q is already provided by a built-in collector", f.Name)})
+			}
+		}
+	}
+	return diags
+}
+
+// registerFacts registers every valid fact in packs whose name is still
+// free, skipping the ones checkFacts reports.
+func registerFacts(packs []*Pack) {
+	for _, p := range packs {
+		for _, f := range p.Facts {
+			if len(f.Validate()) > 0 {
+				continue
+			}
+			if c, ok := checks.LookupCollector(f.Name); ok && c.Name() == f.Name {
+				continue
+			}
+			checks.Register(f.Collector())
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+	"github.com/Shunsuiky0raku/redcheck/pkg/scoring"
+)
+
+// Issue levels.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+// Issue is one finding of Lint.
+type Issue struct {
+	Diagnostic
+	Level string
+}
+
+func (i Issue) String() string {
+	return i.Pos + ": " + i.Level + ": " + i.Msg
+}
+
+var validSeverities = map[string]bool{"critical": true, "high": true, "medium": true, "low": true}
+
+// Lint checks rules beyond what the loader enforces: every fact has a
+// registered collector, severity and category are ones scoring knows,
+// conditions are well-formed and remediation is present.
+func Lint(rules []checks.Rule) []Issue {
+	return lint(rules, nil)
+}
+
+// lint is Lint with the script facts the linted packs define, which are
+// not registered while linting.
+func lint(rules []checks.Rule, packFacts map[string]bool) []Issue {
+	var issues []Issue
+	add := func(r checks.Rule, level, format string, args ...any) {
+		issues = append(issues, Issue{Diagnostic{r.Source, fmt.Sprintf("%s: ", r.ID) + fmt.Sprintf(format, args...)}, level})
+	}
+
+	for _, r := range rules {
+		if strings.TrimSpace(r.Title) == "" {
+			add(r, LevelWarning, "missing title")
+		}
+		if !validSeverities[strings.ToLower(r.Severity)] {
+			add(r, LevelError, "invalid severity %q (want Critical, High, Medium or Low)", r.Severity)
+		}
+		if _, ok := scoring.Weights[r.Category]; !ok {
+			add(r, LevelError, "category %q is not scored and would be counted as Recon (known: %s)", r.Category, knownCategories())
+		}
+		if strings.TrimSpace(r.Remediation) == "" {
+			add(r, LevelWarning, "missing remediation")
+		}
+
+		if r.CISLevel < 0 || r.CISLevel > 2 {
+			add(r, LevelError, "cis_level must be 1 or 2, got %d", r.CISLevel)
+		}
+		for _, p := range r.CISProfiles {
+			if !checks.ValidCISProfile(p) {
+				add(r, LevelError, "unknown cis_profile %q (want server or workstation)", p)
+			}
+		}
+		if len(r.CISProfiles) > 0 && r.CISLevel == 0 {
+			add(r, LevelWarning, "cis_profile without cis_level; benchmark selection ignores the rule")
+		}
+
+		for i, a := range r.Fix {
+			for _, err := range a.Validate() {
+				add(r, LevelError, "fix[%d]: %v", i, err)
+			}
+		}
+
+		cond := r.Condition()
+		if len(cond.Facts()) == 0 {
+			add(r, LevelError, "rule checks no fact")
+		} else {
+			for _, err := range cond.Validate() {
+				add(r, LevelError, "%v", err)
+			}
+			if !cond.IsComposite() && cond.Expected == "" && len(cond.ExpectedAll) == 0 && cond.Operator == "" {
+				add(r, LevelWarning, "no expected value; the rule always passes")
+			}
+		}
+		facts := cond.Facts()
+		if r.When != nil {
+			for _, err := range r.When.Validate() {
+				add(r, LevelError, "when: %v", err)
+			}
+			facts = append(facts, r.When.Facts()...)
+		}
+		for _, f := range facts {
+			if packFacts[f] {
+				continue
+			}
+			if msg := checkFact(f); msg != "" {
+				add(r, LevelError, "%s", msg)
+			}
+		}
+	}
+	return issues
+}
+
+// LintPacks lints packs: loader warnings, duplicate IDs and Lint. Rules in
+// base (usually the built-in pack) are only used to detect IDs that packs
+// would redefine; they are not linted themselves.
+func LintPacks(base []*Pack, packs []*Pack) []Issue {
+	var issues []Issue
+	for _, p := range packs {
+		for _, w := range p.Warnings {
+			issues = append(issues, Issue{w, LevelWarning})
+		}
+	}
+	// script facts first, so rules referencing them lint cleanly; they are
+	// checked, not registered, so linting leaves the registry untouched
+	everything := append(append([]*Pack{}, base...), packs...)
+	for _, d := range checkFacts(everything) {
+		issues = append(issues, Issue{d, LevelError})
+	}
+	packFacts := map[string]bool{}
+	for _, p := range everything {
+		for _, f := range p.Facts {
+			if len(f.Validate()) == 0 {
+				packFacts[f.Name] = true
+			}
+		}
+	}
+
+	_, err := Merge(everything...)
+	var perr *Error
+	if errors.As(err, &perr) {
+		for _, d := range perr.Diagnostics {
+			issues = append(issues, Issue{d, LevelError})
+		}
+	}
+
+	// lint rules as a scan without a profile would run them: ${var}
+	// placeholders replaced by the rule's defaults
+	var all []checks.Rule
+	for _, p := range packs {
+		for _, r := range p.Rules {
+			expanded, err := expandRule(r, nil)
+			if err != nil {
+				issues = append(issues, Issue{Diagnostic{r.Source, err.Error()}, LevelError})
+				continue
+			}
+			all = append(all, expanded)
+		}
+	}
+	return append(issues, lint(all, packFacts)...)
+}
+
+// checkFact returns why fact cannot be collected, or "".
+func checkFact(fact string) string {
+	c, ok := checks.LookupCollector(fact)
+	if !ok {
+		return fmt.Sprintf("no collector implemented for fact %q (see \"redcheck facts list\")", fact)
+	}
+	if checks.IsFamily(c.Name()) && strings.TrimPrefix(fact, c.Name()) == "" {
+		return fmt.Sprintf("fact family %q needs a parameter (%s<value>)", c.Name(), c.Name())
+	}
+	return ""
+}
+
+func knownCategories() string {
+	names := make([]string, 0, len(scoring.Weights))
+	for c := range scoring.Weights {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
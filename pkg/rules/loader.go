@@ -1,36 +1,103 @@
 package rules
 
 import (
-	"gopkg.in/yaml.v3"
-	"io/ioutil"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
 )
 
-type Rule struct {
-	ID          string `yaml:"id"`
-	Title       string `yaml:"title"`
-	Category    string `yaml:"category"`
-	Fact        string `yaml:"fact"`
-	Expected    string `yaml:"expected"`
-	Severity    string `yaml:"severity"`
-	Remediation string `yaml:"remediation"`
+// builtinYAML is the built-in rule pack, compiled into the binary so an
+// installed redcheck (e.g. on the far end of --ssh-host) needs no checkout.
+//
+//go:embed builtin.yaml
+var builtinYAML []byte
+
+// BuiltinFile is the name the built-in pack reports in rule sources.
+const BuiltinFile = "builtin.yaml"
+
+// BuiltInYAML returns the embedded rule pack as shipped, for
+// "redcheck rules export".
+func BuiltInYAML() []byte {
+	return append([]byte(nil), builtinYAML...)
 }
 
-func LoadFromDir(dir string) ([]Rule, error) {
-	var rules []Rule
-	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
-	if err != nil {
-		return nil, err
+// LoadBuiltIn parses the embedded rule pack.
+func LoadBuiltIn() (*Pack, error) {
+	return Parse(BuiltinFile, builtinYAML)
+}
+
+// LoadDir parses every .yml/.yaml file under dir (dir may also name a single
+// file, which must then be .yml/.yaml). Problems from all files are
+// reported together as an *Error; the packs that did parse are still
+// returned alongside it, for "rules lint".
+func LoadDir(dir string) ([]*Pack, error) {
+	var packs []*Pack
+	var diags []Diagnostic
+
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() && !isRuleFile(dir) {
+		return nil, fmt.Errorf("%s: not a rule file (want .yml or .yaml)", dir)
 	}
-	for _, f := range files {
-		data, err := ioutil.ReadFile(f)
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() || !isRuleFile(p) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
 		if err != nil {
-			continue
+			return fmt.Errorf("read %s: %w", p, err)
+		}
+		pack, err := Parse(p, data)
+		var perr *Error
+		if errors.As(err, &perr) {
+			diags = append(diags, perr.Diagnostics...)
+			return nil
 		}
-		var r Rule
-		if err := yaml.Unmarshal(data, &r); err == nil {
-			rules = append(rules, r)
+		if err != nil {
+			return err
 		}
+		packs = append(packs, pack)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(diags) > 0 {
+		return packs, &Error{diags}
+	}
+	return packs, nil
+}
+
+func isRuleFile(p string) bool {
+	return strings.HasSuffix(p, ".yml") || strings.HasSuffix(p, ".yaml")
+}
+
+// Merge concatenates the rules of packs in order. A rule ID defined twice,
+// within a pack or across the built-in and --rules packs, is an error.
+func Merge(packs ...*Pack) ([]checks.Rule, error) {
+	var out []checks.Rule
+	var diags []Diagnostic
+	seen := map[string]string{} // id -> source
+
+	for _, p := range packs {
+		for _, r := range p.Rules {
+			if first, dup := seen[r.ID]; dup {
+				diags = append(diags, Diagnostic{r.Source,
+					fmt.Sprintf("duplicate rule id %q (first defined at %s)", r.ID, first)})
+				continue
+			}
+			seen[r.ID] = r.Source
+			out = append(out, r)
+		}
+	}
+	if len(diags) > 0 {
+		return nil, &Error{diags}
 	}
-	return rules, nil
+	return out, nil
 }
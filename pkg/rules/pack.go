@@ -0,0 +1,221 @@
+// Package rules loads rule packs: the built-in pack compiled into the binary
+// and the files passed with --rules. It is the only place rule YAML is
+// decoded.
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+)
+
+// Schema identifiers a rule pack must declare.
+const (
+	APIVersion   = "redcheck/v1"
+	KindRulePack = "RulePack"
+)
+
+// Pack is one rule pack document:
+//
+//	apiVersion: redcheck/v1
+//	kind: RulePack
+//	metadata:
+//	  name: site-baseline
+//	  version: "1.2.0"
+//	rules:
+//	  - id: SITE-1
+//	    ...
+//
+// A bare list of rules (the pre-v1 format) still loads, with a warning.
+type Pack struct {
+	APIVersion string        `yaml:"apiVersion"`
+	Kind       string        `yaml:"kind"`
+	Metadata   Metadata      `yaml:"metadata"`
+	Rules      []checks.Rule `yaml:"rules"`
+	// Facts are script-backed facts the pack's rules can reference; see
+	// RegisterFacts.
+	Facts []checks.ScriptFact `yaml:"facts"`
+
+	File     string       `yaml:"-"` // where the pack was read from
+	Legacy   bool         `yaml:"-"` // bare rule list without apiVersion/kind
+	Warnings []Diagnostic `yaml:"-"`
+}
+
+// Metadata identifies a pack in reports and diagnostics.
+type Metadata struct {
+	Name        string `yaml:"name"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// Diagnostic is one problem in a rule file. Pos is "file:line" (or just
+// the file when no line is known).
+type Diagnostic struct {
+	Pos string
+	Msg string
+}
+
+func (d Diagnostic) String() string {
+	return d.Pos + ": " + d.Msg
+}
+
+// Error lists every problem that stopped rule packs from loading.
+type Error struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *Error) Error() string {
+	lines := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		lines[i] = d.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func pos(file string, line int) string {
+	if line <= 0 {
+		return file
+	}
+	return file + ":" + strconv.Itoa(line)
+}
+
+// Parse decodes one rule file. Unknown keys are rejected, so a typo such as
+// "expect:" is an error instead of a rule that silently always passes.
+func Parse(file string, data []byte) (*Pack, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, &Error{yamlDiagnostics(file, err)}
+	}
+	if len(root.Content) == 0 {
+		return nil, &Error{[]Diagnostic{{file, "no rules in file"}}}
+	}
+	doc := root.Content[0]
+
+	p := &Pack{File: file}
+	var items []*yaml.Node     // one node per rule, for positions
+	var factItems []*yaml.Node // likewise for facts
+	switch {
+	case doc.Kind == yaml.SequenceNode:
+		p.Legacy = true
+		if err := decodeStrict(data, &p.Rules); err != nil {
+			return nil, &Error{yamlDiagnostics(file, err)}
+		}
+		items = doc.Content
+	case doc.Kind == yaml.MappingNode && !isPackHeader(doc):
+		// a single bare rule
+		p.Legacy = true
+		var r checks.Rule
+		if err := decodeStrict(data, &r); err != nil {
+			return nil, &Error{yamlDiagnostics(file, err)}
+		}
+		p.Rules = []checks.Rule{r}
+		items = []*yaml.Node{doc}
+	case doc.Kind == yaml.MappingNode:
+		if err := decodeStrict(data, p); err != nil {
+			return nil, &Error{yamlDiagnostics(file, err)}
+		}
+		if v := mappingValue(doc, "rules"); v != nil {
+			items = v.Content
+		}
+		if v := mappingValue(doc, "facts"); v != nil {
+			factItems = v.Content
+		}
+	default:
+		return nil, &Error{[]Diagnostic{{pos(file, doc.Line), "expected a RulePack or a list of rules"}}}
+	}
+
+	var diags []Diagnostic
+	if p.Legacy {
+		p.Warnings = append(p.Warnings, Diagnostic{file,
+			fmt.Sprintf("legacy rule list without apiVersion/kind; wrap it in a pack (apiVersion: %s, kind: %s)", APIVersion, KindRulePack)})
+	} else {
+		if p.APIVersion != APIVersion {
+			diags = append(diags, Diagnostic{pos(file, keyLine(doc, "apiVersion")),
+				fmt.Sprintf("unsupported apiVersion %q (this build reads %s)", p.APIVersion, APIVersion)})
+		}
+		if p.Kind != KindRulePack {
+			diags = append(diags, Diagnostic{pos(file, keyLine(doc, "kind")),
+				fmt.Sprintf("unsupported kind %q (want %s)", p.Kind, KindRulePack)})
+		}
+	}
+
+	for i := range p.Rules {
+		line := 0
+		if i < len(items) {
+			line = items[i].Line
+		}
+		p.Rules[i].Source = pos(file, line)
+		if strings.TrimSpace(p.Rules[i].ID) == "" {
+			diags = append(diags, Diagnostic{p.Rules[i].Source, "rule has no id"})
+		}
+	}
+	for i := range p.Facts {
+		line := 0
+		if i < len(factItems) {
+			line = factItems[i].Line
+		}
+		p.Facts[i].Source = pos(file, line)
+	}
+	if len(diags) > 0 {
+		return nil, &Error{diags}
+	}
+	return p, nil
+}
+
+func decodeStrict(data []byte, v any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(v)
+}
+
+func isPackHeader(doc *yaml.Node) bool {
+	return mappingValue(doc, "apiVersion") != nil || mappingValue(doc, "kind") != nil
+}
+
+// mappingValue returns the value node for key in a mapping node.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func keyLine(m *yaml.Node, key string) int {
+	if v := mappingValue(m, key); v != nil {
+		return v.Line
+	}
+	return m.Line
+}
+
+var yamlLineRE = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlDiagnostics turns yaml.v3 errors ("line 5: field expect not found in
+// type checks.Rule") into file:line diagnostics.
+func yamlDiagnostics(file string, err error) []Diagnostic {
+	var msgs []string
+	var te *yaml.TypeError
+	if errors.As(err, &te) {
+		msgs = te.Errors
+	} else {
+		msgs = []string{err.Error()}
+	}
+	out := make([]Diagnostic, 0, len(msgs))
+	for _, m := range msgs {
+		if sub := yamlLineRE.FindStringSubmatch(m); sub != nil {
+			line, _ := strconv.Atoi(sub[1])
+			out = append(out, Diagnostic{pos(file, line), sub[2]})
+			continue
+		}
+		out = append(out, Diagnostic{file, strings.TrimPrefix(m, "yaml: ")})
+	}
+	return out
+}
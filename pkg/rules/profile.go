@@ -0,0 +1,290 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+	"github.com/Shunsuiky0raku/redcheck/pkg/scoring"
+)
+
+// KindProfile is the document kind of a tailoring profile.
+const KindProfile = "Profile"
+
+// Profile tailors the rule catalogue to an estate (--profile):
+//
+//	apiVersion: redcheck/v1
+//	kind: Profile
+//	metadata: { name: bastion }
+//	vars:
+//	  pass_max_days: "180"
+//	rules:
+//	  CIS-5.1.6: { disabled: true, reason: "X11 needed for admin tools" }
+//	  CIS-1.6.1: { expected: "LEGACY", severity: "Low", reason: "appliance" }
+type Profile struct {
+	APIVersion string                  `yaml:"apiVersion"`
+	Kind       string                  `yaml:"kind"`
+	Metadata   Metadata                `yaml:"metadata"`
+	Vars       map[string]string       `yaml:"vars"`
+	Rules      map[string]RuleOverride `yaml:"rules"`
+
+	File string `yaml:"-"`
+}
+
+// RuleOverride changes one rule. Unset fields keep the rule's value.
+type RuleOverride struct {
+	Disabled bool    `yaml:"disabled"`
+	Expected *string `yaml:"expected"`
+	Severity string  `yaml:"severity"`
+	Category string  `yaml:"category"`
+	Reason   string  `yaml:"reason"`
+}
+
+// Tailoring records what a profile changed, for the reports.
+type Tailoring struct {
+	Profile   string            `json:"profile"`
+	File      string            `json:"file"`
+	Vars      map[string]string `json:"vars,omitempty"`
+	Overrides []Override        `json:"overrides"`
+}
+
+// Override is one applied change, e.g. "severity High -> Low".
+type Override struct {
+	ID     string `json:"id"`
+	Change string `json:"change"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// LoadProfile reads and strictly decodes a tailoring profile.
+func LoadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read profile: %w", err)
+	}
+	p := &Profile{File: path}
+	if err := decodeStrict(data, p); err != nil {
+		return nil, &Error{yamlDiagnostics(path, err)}
+	}
+	if p.APIVersion != APIVersion || p.Kind != KindProfile {
+		return nil, &Error{[]Diagnostic{{path, fmt.Sprintf("want apiVersion %s and kind %s, got %q/%q",
+			APIVersion, KindProfile, p.APIVersion, p.Kind)}}}
+	}
+	return p, nil
+}
+
+// Tailor applies profile (nil for none) to the catalogue and expands ${var}
+// placeholders: profile vars first, then each rule's own defaults. It
+// returns the rules to run and what the profile changed.
+func Tailor(all []checks.Rule, profile *Profile) ([]checks.Rule, *Tailoring, error) {
+	var t *Tailoring
+	var vars map[string]string
+	overrides := map[string]RuleOverride{}
+	if profile != nil {
+		t = &Tailoring{Profile: profile.Metadata.Name, File: profile.File, Vars: profile.Vars, Overrides: []Override{}}
+		vars = profile.Vars
+		overrides = profile.Rules
+	}
+
+	var diags []Diagnostic
+	known := map[string]bool{}
+	var out []checks.Rule
+	for _, r := range all {
+		known[r.ID] = true
+		if o, ok := overrides[r.ID]; ok {
+			var changes []string
+			var err error
+			r, changes, err = applyOverride(r, o)
+			if err != nil {
+				diags = append(diags, Diagnostic{profile.File, err.Error()})
+				continue
+			}
+			for _, c := range changes {
+				t.Overrides = append(t.Overrides, Override{ID: r.ID, Change: c, Reason: o.Reason})
+			}
+			if o.Disabled {
+				continue
+			}
+		}
+		expanded, err := expandRule(r, vars)
+		if err != nil {
+			diags = append(diags, Diagnostic{r.Source, err.Error()})
+			continue
+		}
+		out = append(out, expanded)
+	}
+
+	ids := make([]string, 0, len(overrides))
+	for id := range overrides {
+		if !known[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		diags = append(diags, Diagnostic{profile.File, fmt.Sprintf("profile overrides unknown rule %q", id)})
+	}
+
+	if len(diags) > 0 {
+		return nil, nil, &Error{diags}
+	}
+	return out, t, nil
+}
+
+func applyOverride(r checks.Rule, o RuleOverride) (checks.Rule, []string, error) {
+	if o.Disabled {
+		return r, []string{"disabled"}, nil
+	}
+	var changes []string
+	if o.Expected != nil {
+		if r.Fact == "" || len(r.ExpectedAll) > 0 {
+			return r, nil, fmt.Errorf("rule %s: expected can only be overridden on single-fact rules", r.ID)
+		}
+		old := r.Expected
+		if len(r.Values) > 0 {
+			old = strings.Join(r.Values, ",")
+		}
+		changes = append(changes, fmt.Sprintf("expected %q -> %q", old, *o.Expected))
+		// in/not_in read values before expected; the override replaces both
+		r.Expected, r.Values = *o.Expected, nil
+	}
+	if o.Severity != "" {
+		if !validSeverities[strings.ToLower(o.Severity)] {
+			return r, nil, fmt.Errorf("rule %s: invalid severity %q (want Critical, High, Medium or Low)", r.ID, o.Severity)
+		}
+		changes = append(changes, fmt.Sprintf("severity %s -> %s", r.Severity, o.Severity))
+		r.Severity = o.Severity
+	}
+	if o.Category != "" {
+		if _, ok := scoring.Weights[o.Category]; !ok {
+			return r, nil, fmt.Errorf("rule %s: category %q is not scored (known: %s)", r.ID, o.Category, knownCategories())
+		}
+		changes = append(changes, fmt.Sprintf("category %s -> %s", r.Category, o.Category))
+		r.Category = o.Category
+	}
+	return r, changes, nil
+}
+
+var varRE = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandRule substitutes ${name} in the rule's facts, expectations, text
+// and fix values.
+func expandRule(r checks.Rule, vars map[string]string) (checks.Rule, error) {
+	var missing []string
+	expand := func(s string) string {
+		return varRE.ReplaceAllStringFunc(s, func(m string) string {
+			name := varRE.FindStringSubmatch(m)[1]
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			if v, ok := r.Vars[name]; ok {
+				return v
+			}
+			missing = append(missing, name)
+			return m
+		})
+	}
+	expandAll := func(in []string) []string {
+		if in == nil {
+			return nil
+		}
+		out := make([]string, len(in))
+		for i, s := range in {
+			out[i] = expand(s)
+		}
+		return out
+	}
+	var expandCond func(c checks.Condition) checks.Condition
+	expandConds := func(in []checks.Condition) []checks.Condition {
+		if in == nil {
+			return nil
+		}
+		out := make([]checks.Condition, len(in))
+		for i, c := range in {
+			out[i] = expandCond(c)
+		}
+		return out
+	}
+	expandCond = func(c checks.Condition) checks.Condition {
+		c.Fact = expand(c.Fact)
+		c.Expected = expand(c.Expected)
+		c.ExpectedAll = expandAll(c.ExpectedAll)
+		c.Values = expandAll(c.Values)
+		c.All = expandConds(c.All)
+		c.Any = expandConds(c.Any)
+		if c.Not != nil {
+			n := expandCond(*c.Not)
+			c.Not = &n
+		}
+		return c
+	}
+
+	r.Title = expand(r.Title)
+	r.Remediation = expand(r.Remediation)
+	r.Description = expand(r.Description)
+	r.Rationale = expand(r.Rationale)
+	r.Fact = expand(r.Fact)
+	r.Expected = expand(r.Expected)
+	r.ExpectedAll = expandAll(r.ExpectedAll)
+	r.Values = expandAll(r.Values)
+	r.All = expandConds(r.All)
+	r.Any = expandConds(r.Any)
+	if r.Not != nil {
+		n := expandCond(*r.Not)
+		r.Not = &n
+	}
+	if r.When != nil {
+		w := expandCond(*r.When)
+		r.When = &w
+	}
+	if r.Fix != nil {
+		expandMap := func(in map[string]string) map[string]string {
+			if in == nil {
+				return nil
+			}
+			out := make(map[string]string, len(in))
+			for k, v := range in {
+				out[k] = expand(v)
+			}
+			return out
+		}
+		fix := make([]checks.FixAction, len(r.Fix))
+		for i, a := range r.Fix {
+			a.SSHDSet = expandMap(a.SSHDSet)
+			a.SysctlSet = expandMap(a.SysctlSet)
+			a.LoginDefsSet = expandMap(a.LoginDefsSet)
+			if a.FileContent != nil {
+				fc := *a.FileContent
+				fc.Content = expand(fc.Content)
+				a.FileContent = &fc
+			}
+			if a.SudoersDropin != nil {
+				d := *a.SudoersDropin
+				d.Content = expand(d.Content)
+				a.SudoersDropin = &d
+			}
+			fix[i] = a
+		}
+		r.Fix = fix
+	}
+
+	if len(missing) > 0 {
+		return r, fmt.Errorf("rule %s: undefined variable(s) %s (set them under vars: in the rule or profile)",
+			r.ID, strings.Join(dedupe(missing), ", "))
+	}
+	return r, nil
+}
+
+func dedupe(in []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, "${"+s+"}")
+		}
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+)
+
+// KindWaivers is the document kind of a waivers file.
+const KindWaivers = "Waivers"
+
+// WaiverFile lists time-boxed exceptions for known, accepted risks
+// (--waivers):
+//
+//	apiVersion: redcheck/v1
+//	kind: Waivers
+//	waivers:
+//	  - id: CIS-5.4.1
+//	    hosts: ["bastion-*"]
+//	    observed: "root,toor"
+//	    owner: secops
+//	    ticket: SEC-1234
+//	    expires: 2026-12-31
+//	    justification: documented break-glass UID 0 account
+type WaiverFile struct {
+	APIVersion string        `yaml:"apiVersion"`
+	Kind       string        `yaml:"kind"`
+	Waivers    []WaiverEntry `yaml:"waivers"`
+}
+
+// WaiverEntry matches failing results by rule ID and, optionally, by host
+// name glob and exact observed value.
+type WaiverEntry struct {
+	ID            string   `yaml:"id"`
+	Hosts         []string `yaml:"hosts"`
+	Observed      *string  `yaml:"observed"`
+	Owner         string   `yaml:"owner"`
+	Ticket        string   `yaml:"ticket"`
+	Expires       string   `yaml:"expires"`
+	Justification string   `yaml:"justification"`
+
+	expires time.Time
+	source  string
+}
+
+const waiverDate = "2006-01-02"
+
+// LoadWaivers reads and validates a waivers file. Every waiver needs an
+// owner, a ticket, a justification and an expiry date.
+func LoadWaivers(file string) ([]WaiverEntry, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read waivers: %w", err)
+	}
+	var wf WaiverFile
+	if err := decodeStrict(data, &wf); err != nil {
+		return nil, &Error{yamlDiagnostics(file, err)}
+	}
+	if wf.APIVersion != APIVersion || wf.Kind != KindWaivers {
+		return nil, &Error{[]Diagnostic{{file, fmt.Sprintf("want apiVersion %s and kind %s, got %q/%q",
+			APIVersion, KindWaivers, wf.APIVersion, wf.Kind)}}}
+	}
+
+	// line of each list item, for diagnostics and reports
+	var root yaml.Node
+	_ = yaml.Unmarshal(data, &root)
+	var items []*yaml.Node
+	if len(root.Content) > 0 {
+		if v := mappingValue(root.Content[0], "waivers"); v != nil {
+			items = v.Content
+		}
+	}
+
+	var diags []Diagnostic
+	for i := range wf.Waivers {
+		w := &wf.Waivers[i]
+		w.source = file
+		if i < len(items) {
+			w.source = pos(file, items[i].Line)
+		}
+		var missing []string
+		for _, f := range []struct{ name, val string }{
+			{"id", w.ID}, {"owner", w.Owner}, {"ticket", w.Ticket},
+			{"expires", w.Expires}, {"justification", w.Justification},
+		} {
+			if strings.TrimSpace(f.val) == "" {
+				missing = append(missing, f.name)
+			}
+		}
+		if len(missing) > 0 {
+			diags = append(diags, Diagnostic{w.source, "waiver is missing " + strings.Join(missing, ", ")})
+			continue
+		}
+		if w.expires, err = time.Parse(waiverDate, w.Expires); err != nil {
+			diags = append(diags, Diagnostic{w.source, fmt.Sprintf("expires %q is not a YYYY-MM-DD date", w.Expires)})
+		}
+		for _, h := range w.Hosts {
+			if _, err := path.Match(h, ""); err != nil {
+				diags = append(diags, Diagnostic{w.source, fmt.Sprintf("bad host pattern %q: %v", h, err)})
+			}
+		}
+	}
+	if len(diags) > 0 {
+		return nil, &Error{diags}
+	}
+	return wf.Waivers, nil
+}
+
+// legacyObserved is what a failing rule reported before facts were typed,
+// where the flattened typed value differs: CIS-5.4.1 checked the verdict
+// acct.uid0_unique ("false") and now lists acct.uid0_accounts. Lists and
+// bools flatten to their old strings, so they need no entry.
+var legacyObserved = map[string]string{
+	"CIS-5.4.1": "false",
+}
+
+// matches reports whether w applies to res on host. observed compares with
+// the flattened value (lists comma-joined) or the legacy one.
+func (w WaiverEntry) matches(res checks.CheckResult, host string) bool {
+	if w.ID != res.ID {
+		return false
+	}
+	if w.Observed != nil && *w.Observed != res.Observed.String() {
+		if legacy, ok := legacyObserved[res.ID]; !ok || *w.Observed != legacy {
+			return false
+		}
+	}
+	if len(w.Hosts) == 0 {
+		return true
+	}
+	for _, h := range w.Hosts {
+		if ok, _ := path.Match(h, host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyWaivers attaches the first matching waiver to every failing result.
+// A waiver is valid through its expiry date: the result becomes "waived".
+// After that the result stays "fail" with the expired waiver attached.
+func ApplyWaivers(results []checks.CheckResult, waivers []WaiverEntry, host string, now time.Time) {
+	today := now.Format(waiverDate)
+	for i := range results {
+		res := &results[i]
+		if res.Status != "fail" {
+			continue
+		}
+		for _, w := range waivers {
+			if !w.matches(*res, host) {
+				continue
+			}
+			res.Waiver = &checks.Waiver{
+				Owner:         w.Owner,
+				Ticket:        w.Ticket,
+				Expires:       w.Expires,
+				Justification: w.Justification,
+				Expired:       today > w.expires.Format(waiverDate),
+				Source:        w.source,
+			}
+			if !res.Waiver.Expired {
+				res.Status = "waived"
+				res.Reason = fmt.Sprintf("waived until %s by %s (%s): %s", w.Expires, w.Owner, w.Ticket, w.Justification)
+			}
+			break
+		}
+	}
+}
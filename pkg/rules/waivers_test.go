@@ -0,0 +1,34 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shunsuiky0raku/redcheck/pkg/checks"
+)
+
+func TestApplyWaiversObserved(t *testing.T) {
+	str := func(s string) *string { return &s }
+	expires := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	uid0 := checks.StringList([]string{"root", "toor"})
+	tests := []struct {
+		name     string
+		id       string
+		observed *string
+		want     string
+	}{
+		{"any observed", "CIS-5.4.1", nil, "waived"},
+		{"flattened list", "CIS-5.4.1", str("root,toor"), "waived"},
+		{"legacy verdict", "CIS-5.4.1", str("false"), "waived"},
+		{"other value", "CIS-5.4.1", str("root,admin"), "fail"},
+		{"legacy only for its rule", "RC-1.1", str("false"), "fail"},
+	}
+	for _, tt := range tests {
+		results := []checks.CheckResult{{ID: tt.id, Status: "fail", Observed: uid0}}
+		w := WaiverEntry{ID: tt.id, Observed: tt.observed, Owner: "secops", Ticket: "SEC-1", Expires: "2099-01-01", Justification: "test", expires: expires}
+		ApplyWaivers(results, []WaiverEntry{w}, "host", time.Now())
+		if got := results[0].Status; got != tt.want {
+			t.Errorf("%s: status %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
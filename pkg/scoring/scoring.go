@@ -15,7 +15,7 @@ var Weights = map[string]float64{
 type Result interface {
 	GetID() string
 	GetSeverity() string
-	GetStatus() string // "pass"|"fail"|"error"|"na"
+	GetStatus() string // "pass"|"fail"|"error"|"na"|"insufficient_privileges"|"waived"
 	GetCategory() string
 }
 
@@ -74,6 +74,10 @@ func Compute(results []Result) Scores {
 		case "error":
 			// count as small penalty without huge distortion
 			t.errPenalty += 1
+		case "insufficient_privileges":
+			// unknown, not failed: the scan could not look
+		case "waived":
+			// accepted risk with a valid waiver: neutral
 		}
 	}
 